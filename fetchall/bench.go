@@ -0,0 +1,67 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// histBuckets is the number of bars in the -hist histogram.
+const histBuckets = 10
+
+// benchmark fetches each of the URLs -n times, at most -c at a time, and
+// prints the latency distribution and request rate, as ab or hey would,
+// with a histogram if -hist is set. The -warmup fetches before each are
+// made with the same client, so that its connections are open, but not
+// measured. It reports whether any measured fetch failed.
+func benchmark(urls []string) (failed bool) {
+	for _, url := range urls {
+		if *warmup > 0 {
+			fetchAll(repeat(url, *warmup), nil, nil) // discarded: they pay for dialing and TLS handshakes
+		}
+		results := fetchAll(repeat(url, *count), nil, nil)
+		sum := fetcher.Summarize(results)
+		fmt.Printf("%s: %d requests, %d succeeded, %d failed, %.1f requests/s\n",
+			fetcher.RedactURL(url), sum.Total, sum.Succeeded, sum.Failed, sum.Rate())
+		fmt.Printf("  min %s  mean %s  p50 %s  p95 %s  p99 %s  max %s\n",
+			fetcher.FormatDuration(sum.Min), fetcher.FormatDuration(sum.Mean), fetcher.FormatDuration(sum.P50),
+			fetcher.FormatDuration(sum.P95), fetcher.FormatDuration(sum.P99), fetcher.FormatDuration(sum.Max))
+		if *hist {
+			fetcher.WriteHistogram(os.Stdout, results, histBuckets)
+		}
+		failed = failed || sum.Failed > 0
+	}
+	return failed
+}
+
+// repeat returns a list of n copies of url.
+func repeat(url string, n int) []string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = url
+	}
+	return list
+}
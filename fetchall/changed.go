@@ -0,0 +1,63 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// onlyChanged fetches the URLs as a batch and prints those whose content
+// differs from the checksums kept in the -changed file, marked new if they
+// were not there before, and then saves the new checksums to it. It
+// reports whether any fetch failed; failed URLs keep their old checksums.
+func onlyChanged(urls []string) (failed bool) {
+	state, err := fetcher.LoadHashState(*changedFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		return true
+	}
+	*checksum = true // the comparison needs each body's SHA-256
+	for _, r := range fetchAll(urls, nil, nil) {
+		if r.Failed() {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", fetcher.Errors([]fetcher.Result{r}))
+			failed = true
+			continue
+		}
+		_, seen := state[r.URL]
+		if state.Update(r) {
+			what := "changed"
+			if !seen {
+				what = "new"
+			}
+			fmt.Printf("%-7s  %s\n", what, fetcher.RedactURL(r.URL))
+		}
+	}
+	if err := state.Save(*changedFile); err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		return true
+	}
+	return failed
+}
@@ -0,0 +1,70 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// The ANSI escape sequences that color the lines printResult prints.
+const (
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	red    = "\x1b[31m"
+	reset  = "\x1b[0m"
+)
+
+// useColor reports whether printResult colors its lines, as set by
+// colorOutput.
+var useColor bool
+
+// colorOutput reports whether results should be printed in color: stdout is
+// a terminal, -no-color is not set and the NO_COLOR environment variable is
+// empty, as https://no-color.org asks.
+func colorOutput() bool {
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0 // a terminal, not a file or pipe
+}
+
+// colors returns the escape sequences to print before and after the line
+// describing r: green for a 2xx status, yellow for a 3xx and red for a 4xx
+// or 5xx or an error. Both are empty unless useColor is set.
+func colors(r fetcher.Result) (on, off string) {
+	if !useColor {
+		return "", ""
+	}
+	switch {
+	case r.Failed():
+		return red, reset
+	case r.StatusCode >= 300:
+		return yellow, reset
+	case r.StatusCode >= 200:
+		return green, reset
+	}
+	return "", ""
+}
@@ -0,0 +1,76 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// concatenate fetches the URLs as fetchAll does and writes their bodies one
+// after another to the -output-single-file file, or stdout if it is "-",
+// each after a line naming its URL. Unless the bodies go to stdout, a line
+// is printed for each fetch, followed by the totals. It reports whether any
+// fetch failed.
+func concatenate(urls []string, notes []annotation) (failed bool) {
+	var w io.Writer = os.Stdout
+	var f *os.File // the file written, unless it is stdout
+	each := printResult
+	if *singleFile != "-" {
+		var err error
+		if f, err = os.Create(*singleFile); err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			return true
+		}
+		defer f.Close()
+		w = f
+	} else {
+		each = func(r fetcher.Result) {
+			if r.Failed() {
+				fmt.Fprintf(os.Stderr, "fetchall: %v\n", fetcher.Errors([]fetcher.Result{r})) // stdout holds only the bodies
+			}
+		}
+	}
+	var werr error
+	results := fetchAllWith(urls, notes, each, func(ctx context.Context, urls []string, opts fetcher.BatchOptions) []fetcher.Result {
+		results, err := fetcher.FetchConcatenatedContext(ctx, w, urls, opts)
+		werr = err
+		return results
+	})
+	if f != nil && werr == nil {
+		werr = f.Close() // the deferred Close then does nothing
+	}
+	if werr != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", werr)
+		return true
+	}
+	sum := fetcher.Summarize(results)
+	if *singleFile != "-" {
+		fmt.Printf("%d succeeded, %d failed; bodies written to %s\n", sum.Succeeded, sum.Failed, *singleFile)
+	}
+	return sum.Failed > 0
+}
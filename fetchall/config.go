@@ -0,0 +1,60 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// config holds the settings read from the -config file, or nil if there
+// is none.
+var config *fetcher.Config
+
+// loadConfig reads the -config file, if any, into config and copies its
+// proxy and concurrency into -proxy and -c unless they were given on the
+// command line, which wins. Its timeout, headers and auth are applied by
+// newClient, so that every request batchClient sends, in any mode, carries
+// them. It exits if the file cannot be read.
+func loadConfig() {
+	if *configFile == "" {
+		return
+	}
+	c, err := fetcher.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		os.Exit(1)
+	}
+	config = c
+	set := make(map[string]bool) // the flags given on the command line
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["proxy"] && c.Proxy != "" {
+		*proxy = c.Proxy
+	}
+	if !set["c"] && c.Concurrency > 0 {
+		*concurrency = c.Concurrency
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// crawl crawls from each of the URLs to -crawl levels deep, printing each
+// page fetched, and each page skipped as robots.txt asks unless
+// -ignore-robots is set, and then a summary, broken down by host with
+// -by-host. It reports whether any fetch failed.
+func crawl(urls []string) (failed bool) {
+	var results []fetcher.Result
+	blocked := 0
+	opts := fetcher.CrawlOptions{
+		Client:       batchClient, // with its cookies, so that a crawl can follow a login
+		MaxDepth:     *crawlDepth,
+		SameHostOnly: !*allHosts,
+		IgnoreRobots: *noRobots,
+		Blocked: func(url string) {
+			blocked++
+			if !*quiet {
+				fmt.Printf("blocked by robots  %s\n", fetcher.RedactURL(url))
+			}
+		},
+	}
+	for _, seed := range urls {
+		for _, r := range fetcher.CrawlWith(seed, opts) {
+			printResult(r)
+			results = append(results, r)
+		}
+	}
+	sum := fetcher.Summarize(results)
+	fmt.Printf("%d pages: %d succeeded, %d failed, %s", sum.Total, sum.Succeeded, sum.Failed, fetcher.FormatBytes(sum.TotalBytes))
+	if blocked > 0 {
+		fmt.Printf(", %d blocked by robots", blocked)
+	}
+	fmt.Println()
+	if *byHost {
+		printHosts(sum)
+	}
+	return sum.Failed > 0
+}
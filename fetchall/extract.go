@@ -0,0 +1,58 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// extractLinks fetches each of the URLs and prints the links found in it,
+// one per line, so that they can be fed back to fetchall with -i -. Errors
+// go to stderr. It reports whether any page could not be fetched.
+func extractLinks(urls []string) (failed bool) {
+	for _, rawURL := range urls {
+		r, err := fetchResponse(rawURL)
+		if err == nil && r.StatusCode >= 400 {
+			err = &fetcher.StatusError{URL: rawURL, StatusCode: r.StatusCode}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			failed = true
+			continue
+		}
+		base, err := url.Parse(r.FinalURL) // relative links are relative to where any redirects ended
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			failed = true
+			continue
+		}
+		for _, link := range fetcher.ExtractLinks(r.Body, base) {
+			fmt.Println(link)
+		}
+	}
+	return failed
+}
@@ -24,8 +24,20 @@ package main
 // SOFTWARE.
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/mobiledatabooks/go-fetch/fetcher"
@@ -33,35 +45,810 @@ import (
 
 //!+
 
+var (
+	concurrency = flag.Int("c", 0, "maximum number of concurrent fetches (0 means no limit)")
+	deadline    = flag.Duration("deadline", 0, "stop the whole batch after `duration`, such as 60s, cancelling the fetches still outstanding (0 means no limit)")
+	failFast    = flag.Bool("fail-fast", false, "cancel the remaining fetches as soon as one fails")
+	perHost     = flag.Int("per-host", 0, "maximum number of concurrent fetches to any one host (0 means no limit)")
+	retries     = flag.Int("retries", 0, "retry each failed fetch up to `n` times when the failure may pass, such as a 5xx, 429 or connection error")
+	retryDelay  = flag.Duration("retry-delay", 500*time.Millisecond, "with -retries, the backoff before the first retry, doubled for each one after it")
+	retryBudget = flag.Int("retry-budget", 0, "with -retries, make at most `n` retries in the whole batch, however many fetches fail (0 means no limit)")
+	changedFile = flag.String("changed", "", "print only the URLs whose content has changed since the checksums kept in the JSON `file` were saved, then update it")
+	lines       = flag.Bool("lines", false, "print each line of the bodies as it arrives, after its URL if there are several, for streams of newline-delimited records")
+	untilOK     = flag.Bool("until-success", false, "fetch each URL every -interval until it answers with a 2xx status, within -deadline, as when waiting for a service to start")
+	interval    = flag.Duration("interval", time.Second, "with -until-success, the time to wait between attempts")
+	count       = flag.Int("n", 0, "benchmark: fetch each URL `n` times, -c at a time, and report latency statistics instead of the usual output")
+	warmup      = flag.Int("warmup", 0, "with -n, first fetch each URL `n` more times, -c at a time and with the same connections, leaving them out of the statistics")
+	input       = flag.String("i", "", "read newline-separated URLs from `file` (- means stdin)")
+	configFile  = flag.String("config", "", "read default headers, auth, proxy, timeout and concurrency from the JSON `file`; flags override it")
+	globOff     = flag.Bool("globoff", false, "take {a,b} and [1-100] in URLs literally instead of expanding them into many URLs")
+	allowDupes  = flag.Bool("allow-dupes", false, "fetch a URL as many times as it is listed instead of once")
+	wire        = flag.Bool("wire", false, "report the compressed size on the wire as well as the decompressed size")
+	maxBytes    = flag.Int64("max-bytes", 0, "copy at most `n` bytes of each body to stdout (0 means no limit)")
+	head        = flag.Bool("head", false, "issue HEAD requests and report the status and declared length instead of fetching bodies")
+	links       = flag.Bool("check-links", false, "check the URLs with HEAD requests and report them grouped as ok, redirect, client error and server error")
+	extract     = flag.Bool("extract-links", false, "fetch each page and print the http and https links in it, one per line, instead of the usual output")
+	noRobots    = flag.Bool("ignore-robots", false, "with -crawl, fetch pages even when the robots.txt of their site disallows them")
+	sitemap     = flag.Bool("sitemap", false, "treat the URLs as sitemaps, following sitemap indexes and decompressing .xml.gz, and fetch the pages they list instead")
+	crawlDepth  = flag.Int("crawl", 0, "crawl from each URL, following links up to `depth` levels away (0 means no crawl)")
+	allHosts    = flag.Bool("all-hosts", false, "with -crawl, follow links to other hosts as well as the URL's own")
+	mirrorDir   = flag.String("mirror", "", "save each URL under `dir` at a path mirroring the URL (host/path/to/file) instead of printing it")
+	singleFile  = flag.String("output-single-file", "", "write the bodies of all the URLs to `file` (- means stdout), one after another, each after a line \"===== URL =====\"")
+	acceptTypes = flag.String("accept", "", "with -mirror, save only content whose type is in the comma-separated `list`, such as text/html,image/*")
+	saveHeaders = flag.Bool("save-headers", false, "with -mirror, save each response header beside its body in a .headers file")
+	gzipOut     = flag.Bool("gzip-out", false, "with -mirror, save each body compressed with gzip, adding .gz to its name")
+	manifest    = flag.Bool("manifest", false, "with -mirror, write index.json in its dir listing each URL, its file, status, size and SHA-256")
+	replay      = flag.String("replay", "", "fetch again the URLs recorded as failed, with an error or a status other than 2xx, in the -manifest index.json `file` of an earlier run")
+	ordered     = flag.Bool("ordered", false, "print the results in input order rather than as they complete")
+	dryRun      = flag.Bool("dry-run", false, "check and print the normalized URLs without fetching them")
+	format      = flag.String("format", "text", "print the results as `format`: text, the usual human-readable lines, or json, ndjson or csv, instead")
+	jsonOut     = flag.Bool("json", false, "short for -format json")
+	ndjsonOut   = flag.Bool("ndjson", false, "short for -format ndjson: one JSON object per line, written as each fetch completes")
+	metrics     = flag.String("metrics", "", "write Prometheus metrics of the batch (requests by status class, latency histogram, bytes) to `file` (- means stdout)")
+	progress    = flag.Bool("progress", false, "show how many fetches have completed on stderr")
+	contains    = flag.String("contains", "", "fail each fetch whose body does not contain `text`, whatever its status")
+	match       = flag.String("match", "", "fail each fetch whose body does not match the regular expression `re`, whatever its status")
+	slow        = flag.Duration("slow", 0, "flag the fetches that succeed but take longer than `duration`, such as 500ms, and count them in the totals")
+	slowFail    = flag.Bool("slow-fail", false, "with -slow, exit with status 1 if any fetch was slow")
+	hist        = flag.Bool("hist", false, "after the totals, draw a histogram of the latencies of the successful fetches")
+	byHost      = flag.Bool("by-host", false, "after the totals, print the requests, failures and bytes of each host")
+	byLabel     = flag.Bool("by-label", false, "after the totals, print the requests, failures and bytes of each label given by \"label=name url\" entries")
+	quiet       = flag.Bool("quiet", false, "print nothing for the fetches that succeed, only the failures and the totals")
+	errorsOnly  = flag.Bool("errors-only", false, "same as -quiet")
+	trace       = flag.Bool("trace", false, "print the redirects followed and the DNS, connect, TLS, first byte and transfer times of each fetch")
+	rate        = flag.Float64("rate", 0, "start at most `n` requests per second across all fetches (0 means no limit)")
+	jitterMax   = flag.Duration("jitter", 0, "wait a random time up to `duration`, such as 200ms, before each fetch so that they do not all start at once")
+	checksum    = flag.Bool("checksum", false, "print the SHA-256 of each body alongside its size")
+	speed       = flag.Bool("speed", false, "show the live transfer rate of each body on stderr while it downloads")
+	decode      = flag.Bool("decode", false, "for a single URL, transcode the content to UTF-8 from the charset its Content-Type or <meta> tag declares, and report the charset")
+	replMode    = flag.Bool("repl", false, "read URLs to fetch from stdin one line at a time, printing each result as it comes, with commands such as :header, :timeout and :c to change the fetches after them; :help lists them")
+	cookies     = flag.Bool("cookies", false, "keep the cookies set by responses and send them with later requests in the batch")
+	proxy       = flag.String("proxy", "", "send the requests through the proxy at `url` (http, https or socks5) instead of $HTTP_PROXY")
+	ipv4        = flag.Bool("4", false, "connect over IPv4 only")
+	ipv6        = flag.Bool("6", false, "connect over IPv6 only")
+	dnsServer   = flag.String("dns", "", "look host names up with the DNS server at `addr`, such as 8.8.8.8 or 1.1.1.1:53, instead of the system's resolver")
+	dnsTCP      = flag.Bool("dns-tcp", false, "with -dns, query the DNS server over TCP instead of UDP")
+	dialTries   = flag.Int("dial-attempts", 0, "attempt each connection, DNS lookup included, up to `n` times before failing (0 means once)")
+	unixSocket  = flag.String("unix", "", "connect to the Unix domain socket at `path`, such as /var/run/docker.sock, whatever the host in the URLs")
+	caCert      = flag.String("cacert", "", "verify server certificates against the CAs in the PEM `file` instead of the system's")
+	maxSize     = flag.Int64("max-size", 0, "refuse, as too large, a response whose body is over `n` bytes, before reading it if its length is declared (0 means no limit)")
+	maxHeader   = flag.Int64("max-header-size", 0, "refuse, as too large, a response whose header is over `n` bytes (0 means net/http's default limit)")
+	maxIdle     = flag.Int("max-idle", 0, "keep at most `n` idle connections open for reuse across all hosts (0 means 100)")
+	idlePerHost = flag.Int("max-idle-per-host", 0, "keep at most `n` idle connections open for reuse to each host (0 means 16)")
+	hostConns   = flag.Int("max-conns-per-host", 0, "open at most `n` connections, busy or idle, to each host (0 means no limit)")
+	idleTimeout = flag.Duration("idle-timeout", 0, "close connections left idle for `d` (0 means 90s)")
+	http1       = flag.Bool("http1.1", false, "make every request over HTTP/1.1, never HTTP/2")
+	noRedirect  = flag.Bool("no-redirect", false, "report 3xx responses and where they point instead of following them")
+	clientCert  = flag.String("cert", "", "present the client certificate in the PEM `file` to servers that ask for one (mutual TLS); needs -key")
+	clientKey   = flag.String("key", "", "the private key, in the PEM `file`, of the -cert certificate")
+	localAddr   = flag.String("local-addr", "", "make connections from the local IP address `ip`, choosing the network interface they use")
+	noColor     = flag.Bool("no-color", false, "print results without color even when stdout is a terminal")
+	verbose     = flag.Bool("v", false, "log each request, response, redirect and retry to stderr")
+	debug       = flag.Bool("vv", false, "like -v, and log the request and response headers too")
+)
+
 func main() { // Fetch prints the content found at each specified URL.
+	flag.Parse()
+	switch { // diagnostics go to stderr so that stdout stays clean for piping
+	case *debug:
+		fetcher.SetLogger(log.New(os.Stderr, "fetchall: ", log.Lmicroseconds), fetcher.LevelDebug)
+	case *verbose:
+		fetcher.SetLogger(log.New(os.Stderr, "fetchall: ", log.Lmicroseconds), fetcher.LevelInfo)
+	}
+	switch {
+	case *ipv4 && *ipv6:
+		fmt.Fprintln(os.Stderr, "fetchall: -4 and -6 cannot be used together")
+		os.Exit(1)
+	case *ipv4:
+		fetcher.SetNetwork("tcp4") // for every fetch that goes through the fetcher package's transports
+	case *ipv6:
+		fetcher.SetNetwork("tcp6")
+	}
+	useColor = colorOutput()
+	loadConfig()              // before anything reads the flags it may set
+	batchClient = newClient() // now, so that a bad -cert or -cacert is reported before anything is fetched
+	if batchClient != nil {
+		fetcher.Transport = batchClient.Transport // so that every mode, not only a batch, goes by it and sends the -config headers
+	}
+	bodyCheck = newCheck()
+	if *retryBudget > 0 {
+		budget = fetcher.NewRetryBudget(*retryBudget)
+	}
+	if *jsonOut {
+		*format = "json"
+	}
+	if *ndjsonOut {
+		*format = "ndjson"
+	}
+	if *errorsOnly {
+		*quiet = true
+	}
+	if *replMode { // ad-hoc fetching, one URL at a time
+		if repl(os.Stdin) {
+			os.Exit(1) // the last fetch failed
+		}
+		return
+	}
+	raw, err := collectURLs(*input, flag.Args()) // the URLs to fetch are those in the -i file and the arguments left after the flags
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err) // report the error and stop: there is no list of URLs to fetch
+		os.Exit(1)
+	}
+	if *replay != "" {
+		raw = append(raw, replayURLs()...) // the URLs that failed last time, after any given
+	}
+	urls, notes, dupes, invalid := normalizeURLs(raw, !*globOff, *allowDupes) // expand patterns, add missing schemes and drop duplicates
+	if dupes > 0 {
+		fmt.Fprintf(os.Stderr, "fetchall: collapsed %d duplicate URLs\n", dupes) // requests saved
+	}
+	if *dryRun { // check the list without touching the network
+		for _, url := range urls {
+			fmt.Println(fetcher.RedactURL(url)) // print each valid URL as it would be fetched, without its password
+		}
+		for _, err := range invalid {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err) // name each bad URL
+		}
+		fmt.Fprintf(os.Stderr, "%d valid, %d invalid\n", len(urls), len(invalid))
+		if len(invalid) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(invalid) > 0 {
+		for _, err := range invalid {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err) // name each bad URL
+		}
+		os.Exit(1) // reject the list before fetching anything
+	}
+
+	if *sitemap { // fetch the pages the sitemaps list in their place
+		urls, notes = sitemapPages(urls), nil
+	}
+
+	if *changedFile != "" { // change detection between runs
+		if onlyChanged(urls) {
+			os.Exit(1) // some URLs could not be fetched
+		}
+		return
+	}
+
+	if *lines { // follow streaming endpoints
+		if streamLines(urls) {
+			os.Exit(1) // some stream could not be read
+		}
+		return
+	}
+
+	if *untilOK { // wait for a health check to pass
+		if waitForURLs(urls) {
+			os.Exit(1) // some URL never came up
+		}
+		return
+	}
+
+	if *links { // link checker: classify each URL instead of fetching it
+		if checkLinks(urls) {
+			os.Exit(1) // some links are broken
+		}
+		return
+	}
+
+	if *extract { // crawler seed: print the links for feeding back in
+		if extractLinks(urls) {
+			os.Exit(1) // some pages could not be fetched
+		}
+		return
+	}
+
+	if *crawlDepth > 0 { // follow the links from each URL
+		if crawl(urls) {
+			os.Exit(1) // some pages could not be fetched
+		}
+		return
+	}
+
+	if *mirrorDir != "" { // save a browsable local copy
+		if mirror(urls) {
+			os.Exit(1) // some URLs could not be saved
+		}
+		return
+	}
+
+	if len(formFields) > 0 || len(formFiles) > 0 { // test a form or upload endpoint
+		if postForm(urls) {
+			os.Exit(1) // some posts failed
+		}
+		return
+	}
+
+	if *singleFile != "" { // collect the bodies in one file
+		if concatenate(urls, notes) {
+			os.Exit(1) // some URLs could not be fetched or written
+		}
+		return
+	}
+
+	if *count > 0 { // mini load test
+		if benchmark(urls) {
+			os.Exit(1) // some requests failed
+		}
+		return
+	}
+
+	if f := formatter(*format); f != nil { // machine-readable output: nothing but the results goes to stdout
+		if *format != "ndjson" {
+			*ordered = true // results in input order, so that runs can be compared; ndjson streams them as they complete
+		}
+		var ferr error // the first error writing the output
+		results := fetchAll(urls, notes, func(r fetcher.Result) {
+			if err := f.Format(r); err != nil && ferr == nil {
+				ferr = err
+			}
+		})
+		if err := f.Flush(fetcher.Summarize(results)); err != nil && ferr == nil {
+			ferr = err
+		}
+		if ferr != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", ferr)
+			os.Exit(1)
+		}
+		writeMetrics(results)
+		if fetcher.Summarize(results).Failed > 0 {
+			os.Exit(1) // let scripts and CI see that something went wrong
+		}
+		return
+	}
+
+	if len(urls) == 1 && !*head && !*wire && !*checksum && !*trace && *metrics == "" && bodyCheck == nil && *retries == 0 && *deadline == 0 && *slow == 0 && (notes == nil || notes[0].timeout == 0) { // a single URL just streams to stdout, like curl
+		start := time.Now()
+		n, ok := streamURL(urls[0], fetchStream) // which fails on a 4xx or 5xx status
+		if !ok {
+			os.Exit(1)
+		}
+		if !*quiet {
+			fmt.Fprintf(os.Stderr, "fetchall: %s in %s\n", fetcher.FormatBytes(n), fetcher.FormatDuration(time.Since(start))) // on stderr, so that stdout holds only the content
+		}
+		return
+	}
+
+	if !*head && !*quiet && *rate == 0 && *deadline == 0 { // the sequential fetchers know nothing of -rate or -deadline
+		ctx, stop := interruptible() // Ctrl-C stops the passes, as it does the batch
+		sequential(ctx, urls)        // fetch the URLs one at a time with each of the sequential fetchers
+		stop()
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "fetchall: interrupted during the sequential passes; the batch was not run")
+			os.Exit(130) // the shell's convention for death by SIGINT
+		}
+	}
+
+	fmt.Println("fetcher.FetchAll: Fetching URLs...")                     // print message to stdout
+	start := time.Now()                                                   // start a timer to measure the time it takes to fetch the URLs
+	results := fetchAll(urls, notes, printResult)                         // fetch the URLs, at most -c at a time, printing each result as it arrives
+	fmt.Printf("%s elapsed\n", fetcher.FormatDuration(time.Since(start))) // print the time elapsed since the start of the timer
+
+	writeMetrics(results)
+
+	sum := fetcher.Summarize(results) // total up the results
+	fmt.Printf("%d succeeded, %d failed, %s, mean %s, p95 %s\n", sum.Succeeded, sum.Failed,
+		fetcher.FormatBytes(sum.TotalBytes), fetcher.FormatDuration(sum.Mean), fetcher.FormatDuration(sum.P95))
+	fmt.Printf("%s of fetching in %s of wall-clock time\n", fetcher.FormatDuration(sum.Busy), fetcher.FormatDuration(sum.Wall)) // the first over the second is the speedup over fetching one at a time
+	if budget != nil {
+		fmt.Printf("retry budget: %d of %d used, %d retries refused\n", budget.Used(), budget.Limit(), budget.Refused())
+	}
+	slowCount := 0
+	for _, r := range results {
+		if r.Slow(*slow) {
+			slowCount++
+		}
+	}
+	if *slow > 0 {
+		fmt.Printf("%d slow, over %s\n", slowCount, fetcher.FormatDuration(*slow))
+	}
+	if *hist {
+		fetcher.WriteHistogram(os.Stdout, results, histBuckets)
+	}
+	if *byHost {
+		printHosts(sum)
+	}
+	if *byLabel {
+		printLabels(sum)
+	}
+	if sum.Failed > 0 && *quiet {
+		os.Exit(1) // every failure has been printed already
+	}
+	if sum.Failed > 0 {
+		var failed []string // the URLs that could not be fetched
+		for _, r := range results {
+			if r.Failed() {
+				failed = append(failed, fetcher.RedactURL(r.URL))
+			}
+		}
+		fmt.Printf("failed: %s\n", strings.Join(failed, " ")) // list the failed URLs
+		os.Exit(1)                                            // let scripts and CI see that something went wrong
+	}
+	if *slowFail && slowCount > 0 {
+		os.Exit(1) // slow counts as failing only when asked
+	}
+}
+
+// formatter returns the formatter for -format name writing to stdout, or
+// nil for text, which is printed by main and printResult. It exits if name
+// is unknown.
+func formatter(name string) fetcher.Formatter {
+	switch name {
+	case "text":
+		return nil
+	case "json":
+		return fetcher.NewJSONFormatter(os.Stdout)
+	case "ndjson":
+		return fetcher.NewNDJSONFormatter(os.Stdout)
+	case "csv":
+		return fetcher.NewCSVFormatter(os.Stdout)
+	}
+	fmt.Fprintf(os.Stderr, "fetchall: unknown -format %q: want text, json, ndjson or csv\n", name)
+	os.Exit(1)
+	return nil
+}
+
+// printResult prints one line describing r: its time, sizes, checksum and
+// URL, or its error. With -trace, each redirect followed, with its status
+// and time, and the protocol and the phases of the fetch follow beneath.
+// Fetches slower than -slow are marked as slow. With -quiet, only failures
+// and slow fetches are printed. On a terminal, each line is colored by its
+// status unless -no-color is set.
+func printResult(r fetcher.Result) {
+	on, off := colors(r)
+	if r.Err != nil {
+		fmt.Printf("%s%v%s\n", on, r.Err, off) // print the error instead of the result
+		return
+	}
+	if *quiet && !r.Slow(*slow) {
+		if r.Failed() {
+			fmt.Printf("%s%v%s\n", on, fetcher.Errors([]fetcher.Result{r}), off) // a 4xx or 5xx status, with the URL it came from
+		}
+		return
+	}
+	fmt.Printf("%s%8s  ", on, fetcher.FormatDuration(r.Elapsed))
+	if *head {
+		length := "-" // the server did not say
+		if r.ContentLength >= 0 {
+			length = fetcher.FormatBytes(r.ContentLength)
+		}
+		fmt.Printf("%d  %9s  %s", r.StatusCode, length, fetcher.RedactURL(r.URL)) // there is no body, so print what the server declared
+		if r.StatusCode >= 300 && r.StatusCode < 400 {
+			fmt.Printf(" -> %s", r.Header.Get("Location"))
+		}
+		if r.Slow(*slow) {
+			fmt.Print("  slow")
+		}
+		fmt.Println(off)
+		return
+	}
+	if *wire {
+		fmt.Printf("%9s  ", fetcher.FormatBytes(r.WireBytes)) // the compressed size comes before the decompressed one
+	}
+	fmt.Printf("%9s  ", fetcher.FormatBytes(r.Bytes))
+	if *checksum {
+		fmt.Printf("%s  ", r.SHA256)
+	}
+	fmt.Print(fetcher.RedactURL(r.URL)) // never print a password given in the URL
+	if r.StatusCode >= 300 && r.StatusCode < 400 {
+		fmt.Printf("  %d -> %s", r.StatusCode, r.Header.Get("Location")) // a redirect not followed, with -no-redirect
+	}
+	if r.Slow(*slow) {
+		fmt.Print("  slow")
+	}
+	fmt.Println(off)
+	if *trace {
+		for _, h := range r.Hops {
+			fmt.Printf("       %d after %s  %s\n", h.StatusCode, fetcher.FormatDuration(h.Elapsed), fetcher.RedactURL(h.URL)) // each redirect on the way
+		}
+		t := r.Timing
+		fmt.Printf("       %s  dns %v  connect %v  tls %v  first byte %v  transfer %v\n",
+			r.Proto, t.DNS, t.Connect, t.TLSHandshake, t.FirstByte, t.Transfer)
+	}
+}
+
+// fetchAll fetches the URLs, at most -c at a time, -per-host to each host
+// and -rate per second, each after a random wait of up to -jitter, with
+// batchClient, retrying failures as -retries says within the retry budget,
+// checking each body against -contains and -match and showing the progress
+// on stderr if -progress is set. Ctrl-C, or the end of -deadline, cancels
+// the fetches still in progress. Each URL is labelled, and given its own
+// timeout, as the annotation of the same index in notes, which may be nil,
+// says. If each is not nil it is called with every result as it completes,
+// or in input order with -ordered.
+func fetchAll(urls []string, notes []annotation, each func(fetcher.Result)) []fetcher.Result {
+	return fetchAllWith(urls, notes, each, fetcher.FetchBatchContext)
+}
+
+// fetchAllWith is like fetchAll but runs the batch with batch, which is
+// given the options fetchAll would use.
+func fetchAllWith(urls []string, notes []annotation, each func(fetcher.Result),
+	batch func(context.Context, []string, fetcher.BatchOptions) []fetcher.Result) []fetcher.Result {
+	opts := fetcher.BatchOptions{
+		Concurrency: *concurrency,
+		PerHost:     *perHost,
+		FailFast:    *failFast,
+		Limiter:     fetcher.NewLimiter(*rate), // nil, meaning no limit, unless -rate is set
+		Jitter:      *jitterMax,
+		Checksum:    *checksum,
+		Ordered:     *ordered,
+		Retries:     *retries,
+		RetryDelay:  *retryDelay,
+		RetryBudget: budget,
+	}
+	for _, n := range notes {
+		opts.Labels = append(opts.Labels, n.label)
+		opts.Timeouts = append(opts.Timeouts, n.timeout)
+	}
+	if *head {
+		opts.Method = http.MethodHead
+	}
+	if each != nil {
+		opts.OnResult = func(i int, r fetcher.Result) { each(r) }
+	}
+	opts.Check = bodyCheck
+	opts.Client = batchClient
+	if *progress {
+		opts.Progress = func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d completed", done, total) // \r returns to the start of the line so the count updates in place
+		}
+	}
+	ctx, stop := interruptible() // Ctrl-C cancels the batch but still leaves the results to report
+	defer stop()
+	if *deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *deadline) // bounds the whole batch, unlike the per-request timeout
+		defer cancel()
+	}
+	results := batch(ctx, urls, opts)
+	if *progress {
+		fmt.Fprintln(os.Stderr) // end the progress line
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		var unfinished []string // the URLs cut off by the deadline
+		for _, r := range results {
+			if errors.Is(r.Err, context.DeadlineExceeded) {
+				unfinished = append(unfinished, fetcher.RedactURL(r.URL))
+			}
+		}
+		fmt.Fprintf(os.Stderr, "fetchall: -deadline %v passed; %d of %d fetches never completed: %s\n",
+			*deadline, len(unfinished), len(urls), strings.Join(unfinished, " "))
+	} else if ctx.Err() != nil {
+		completed := 0
+		for _, r := range results {
+			if !errors.Is(r.Err, context.Canceled) {
+				completed++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "fetchall: interrupted after %d of %d fetches completed\n", completed, len(urls))
+	}
+	return results
+}
+
+// printHosts prints a table of the requests, failures and bytes of each
+// host in sum, those that sent the most bytes first.
+func printHosts(sum fetcher.Summary) {
+	hosts := make([]string, 0, len(sum.ByHost))
+	width := len("host")
+	for host := range sum.ByHost {
+		hosts = append(hosts, host)
+		if len(host) > width {
+			width = len(host)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		a, b := sum.ByHost[hosts[i]], sum.ByHost[hosts[j]]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return hosts[i] < hosts[j]
+	})
+	fmt.Printf("%-*s  %8s  %6s  %9s\n", width, "host", "requests", "failed", "bytes")
+	for _, host := range hosts {
+		h := sum.ByHost[host]
+		if host == "" {
+			host = "-" // file URLs, which have no host
+		}
+		fmt.Printf("%-*s  %8d  %6d  %9s\n", width, host, h.Requests, h.Failed, fetcher.FormatBytes(h.Bytes))
+	}
+}
+
+// printLabels prints a table of the requests, failures and bytes of the
+// URLs with each label in sum, in the order of the labels.
+func printLabels(sum fetcher.Summary) {
+	labels := make([]string, 0, len(sum.ByLabel))
+	width := len("label")
+	for label := range sum.ByLabel {
+		labels = append(labels, label)
+		if len(label) > width {
+			width = len(label)
+		}
+	}
+	sort.Strings(labels)
+	fmt.Printf("%-*s  %8s  %6s  %9s\n", width, "label", "requests", "failed", "bytes")
+	for _, label := range labels {
+		l := sum.ByLabel[label]
+		if label == "" {
+			label = "-" // URLs without a label
+		}
+		fmt.Printf("%-*s  %8d  %6d  %9s\n", width, label, l.Requests, l.Failed, fetcher.FormatBytes(l.Bytes))
+	}
+}
+
+// writeMetrics writes the Prometheus metrics of results to the -metrics
+// file, if it is set, exiting if it cannot.
+func writeMetrics(results []fetcher.Result) {
+	if *metrics == "" {
+		return
+	}
+	w := os.Stdout
+	if *metrics != "-" {
+		f, err := os.Create(*metrics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := fetcher.WriteMetrics(w, results); err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: -metrics: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// bodyCheck is the check fetchAll applies to each body, as built by
+// newCheck.
+var bodyCheck func([]byte) error
+
+// newCheck returns a check that a body passes if it contains -contains and
+// matches -match, or nil if neither is set. It exits if -match is not a
+// valid regular expression.
+func newCheck() func([]byte) error {
+	var checks []func([]byte) error
+	if *contains != "" {
+		checks = append(checks, fetcher.BodyContains(*contains))
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: -match: %v\n", err)
+			os.Exit(1)
+		}
+		checks = append(checks, fetcher.BodyMatches(re))
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	return func(body []byte) error {
+		for _, check := range checks {
+			if err := check(body); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// budget is the -retry-budget shared by every fetch, or nil if there is no
+// limit.
+var budget *fetcher.RetryBudget
+
+// batchClient is the client fetchAll uses, as built by newClient.
+var batchClient *http.Client
+
+// newClient returns the client called for by -proxy, -unix, -local-addr,
+// -cacert, -cert, -no-redirect, -max-size, -max-header-size, -http1.1, the
+// connection pool flags, -dns, -dial-attempts, -cookies and the -config
+// timeout, headers and auth, or nil, meaning the package's shared client,
+// if none of them is set. It exits if any of them is invalid.
+func newClient() *http.Client {
+	var opts fetcher.ClientOptions
+	if *proxy != "" {
+		u, err := fetcher.ParseProxy(*proxy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Proxy = u
+	}
+	opts.UnixSocket = *unixSocket
+	opts.NoRedirect = *noRedirect
+	opts.HTTP1 = *http1
+	opts.MaxIdleConns, opts.MaxIdleConnsPerHost, opts.MaxConnsPerHost = *maxIdle, *idlePerHost, *hostConns
+	opts.IdleConnTimeout = *idleTimeout
+	opts.DNSServer, opts.DialAttempts = *dnsServer, *dialTries
+	if *dnsTCP {
+		if *dnsServer == "" {
+			fmt.Fprintln(os.Stderr, "fetchall: -dns-tcp needs -dns")
+			os.Exit(1)
+		}
+		opts.DNSNetwork = "tcp"
+	}
+	opts.MaxContentLength, opts.MaxHeaderBytes = *maxSize, *maxHeader
+	if *localAddr != "" {
+		if opts.LocalAddr = net.ParseIP(*localAddr); opts.LocalAddr == nil {
+			fmt.Fprintf(os.Stderr, "fetchall: -local-addr: invalid IP address %q\n", *localAddr)
+			os.Exit(1)
+		}
+	}
+	if *caCert != "" {
+		pool, err := fetcher.LoadCertPool(*caCert)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: -cacert: %v\n", err)
+			os.Exit(1)
+		}
+		opts.RootCAs = pool
+	}
+	if *clientCert != "" || *clientKey != "" {
+		if *clientCert == "" || *clientKey == "" {
+			fmt.Fprintln(os.Stderr, "fetchall: -cert and -key must be given together")
+			os.Exit(1)
+		}
+		cert, err := tls.LoadX509KeyPair(*clientCert, *clientKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: -cert %s -key %s: %v\n", *clientCert, *clientKey, err)
+			os.Exit(1)
+		}
+		opts.Certificates = []tls.Certificate{cert}
+	}
+	if config != nil {
+		opts.Timeout = config.Timeout // zero, meaning the default, if it sets none
+		opts.Headers = config.RequestHeaders()
+	}
+	if opts.Proxy == nil && opts.UnixSocket == "" && opts.LocalAddr == nil && opts.RootCAs == nil && opts.Certificates == nil &&
+		opts.Timeout == 0 && !opts.NoRedirect && opts.MaxContentLength == 0 && opts.MaxHeaderBytes == 0 &&
+		!opts.HTTP1 && opts.MaxIdleConns == 0 && opts.MaxIdleConnsPerHost == 0 && opts.MaxConnsPerHost == 0 &&
+		opts.IdleConnTimeout == 0 && opts.DNSServer == "" && opts.DialAttempts <= 1 && len(opts.Headers) == 0 && !*cookies {
+		return nil
+	}
+	client := fetcher.NewClient(opts) // one client, so the fetches share its connections
+	if *cookies {
+		client.Jar, _ = cookiejar.New(nil) // cookiejar.New never fails without options
+	}
+	return client
+}
+
+// sequential fetches the URLs one after another with each of the sequential
+// fetchers in turn, printing the content and the time each pass took. It
+// stops before the next URL once ctx is cancelled; the fetch in progress
+// runs to its end.
+func sequential(ctx context.Context, urls []string) {
 	fmt.Println("fetcher.FetchWithBuffer: Fetching URLs...") // print message to stdout
 	start := time.Now()                                      // start a timer to measure the time it takes to fetch the URLs
-	for _, url := range os.Args[1:] {                        // for each URL in the command line arguments
+	for _, url := range urls {                               // for each URL to fetch
+		if ctx.Err() != nil {
+			return // interrupted
+		}
 		fetcher.FetchWithBuffer(url) // fetch the URL and print the content
 	}
-	fmt.Printf("%.2fs elapsed\n", time.Since(start).Seconds()) // print the time elapsed since the start of the timer
+	fmt.Printf("%s elapsed\n", fetcher.FormatDuration(time.Since(start))) // print the time elapsed since the start of the timer
 
 	fmt.Println()
 
 	fmt.Println("fetcher.Fetch: Fetching URLs...") // print message to stdout
 	start = time.Now()                             // start a timer to measure the time it takes to fetch the URLs
-	for _, url := range os.Args[1:] {              // for each URL in the command line arguments
+	for _, url := range urls {                     // for each URL to fetch
+		if ctx.Err() != nil {
+			return // interrupted
+		}
 		fetcher.Fetch(url) // fetch the URL and print the content
 	}
-	fmt.Printf("%.2fs elapsed\n", time.Since(start).Seconds()) // print the time elapsed since the start of the timer
+	fmt.Printf("%s elapsed\n", fetcher.FormatDuration(time.Since(start))) // print the time elapsed since the start of the timer
+
+	fmt.Println()
+
+	fmt.Println("fetcher.FetchTo: Fetching URLs...") // print message to stdout
+	start = time.Now()                               // start a timer to measure the time it takes to fetch the URLs
+	for _, url := range urls {                       // for each URL to fetch
+		if ctx.Err() != nil {
+			return // interrupted
+		}
+		streamURL(url, fetcher.FetchTo) // copy the content to stdout, reporting any error and carrying on with the next URL
+	}
+	fmt.Printf("%s elapsed\n", fetcher.FormatDuration(time.Since(start))) // print the time elapsed since the start of the timer
 
 	fmt.Println()
+}
+
+// streamURL copies the content found at url straight to stdout with fetch,
+// or at most -max-bytes of it, or showing the transfer rate with -speed, or
+// transcoded to UTF-8 with -decode.
+// It reports any error on stderr and returns the number of bytes copied
+// and whether the fetch succeeded.
+func streamURL(url string, fetch func(io.Writer, string) (int64, error)) (int64, bool) {
+	var (
+		n   int64
+		err error
+	)
+	switch {
+	case *maxBytes > 0:
+		var truncated bool
+		n, truncated, err = fetcher.FetchLimited(os.Stdout, url, *maxBytes) // copy at most -max-bytes of the content
+		if err == nil && truncated {
+			fmt.Fprintf(os.Stderr, "fetchall: %s: truncated after %d bytes\n", fetcher.RedactURL(url), *maxBytes) // say that there was more to read
+		}
+	case *speed:
+		n, err = showSpeed(url)
+	case *decode:
+		n, err = fetchDecoded(url)
+	default:
+		n, err = fetch(os.Stdout, url)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		return n, false
+	}
+	return n, true
+}
+
+// fetchStream is fetcher.FetchStream sending the request with batchClient,
+// and its cookies, if it is set.
+func fetchStream(w io.Writer, url string) (int64, error) {
+	if batchClient != nil {
+		return fetcher.FetchStreamWithClient(batchClient, w, url)
+	}
+	return fetcher.FetchStream(w, url)
+}
+
+// fetchResponse is fetcher.FetchResponse sending the request with
+// batchClient, and its cookies, if it is set.
+func fetchResponse(url string) (*fetcher.Result, error) {
+	if batchClient != nil {
+		return fetcher.FetchResponseWithClient(batchClient, url)
+	}
+	return fetcher.FetchResponse(url)
+}
+
+// fetchDecoded copies the content found at url to stdout transcoded to
+// UTF-8 from the charset its Content-Type or a <meta> tag names, reporting
+// the charset on stderr unless -quiet is set. Content in a charset that
+// cannot be decoded is copied as it is, with a warning.
+func fetchDecoded(url string) (int64, error) {
+	r, err := fetchResponse(url)
+	if err == nil && r.Failed() {
+		err = fetcher.Errors([]fetcher.Result{*r}) // a 4xx or 5xx status
+	}
+	if err != nil {
+		return 0, err
+	}
+	charset := fetcher.DetectCharset(r.Header.Get("Content-Type"), r.Body)
+	body, err := fetcher.DecodeCharset(r.Body, charset)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "fetchall: %v; printing the content undecoded\n", err)
+		body = r.Body
+	case *quiet:
+	case charset == "":
+		fmt.Fprintln(os.Stderr, "fetchall: no charset declared; assuming UTF-8")
+	default:
+		fmt.Fprintf(os.Stderr, "fetchall: charset %s, decoded to UTF-8\n", charset)
+	}
+	n, err := os.Stdout.Write(body)
+	return int64(n), err
+}
 
-	fmt.Println("fetcher.FetchConcurrent: Fetching URLs...") // print message to stdout
-	start = time.Now()                                       // start a timer to measure the time it takes to fetch the URLs
-	ch := make(chan string)                                  // make a channel to receive the results of the fetching of the URLs in parallel (concurrent) and return the results to the channel
-	for _, url := range os.Args[1:] {                        // for each URL in the command line arguments (concurrent)
-		go fetcher.FetchConcurrent(url, ch) // start a goroutine to fetch the URL and return the result to the channel
+// showSpeed copies the content found at url to stdout with batchClient, if
+// it is set, keeping the transfer rate so far up to date on a line of
+// stderr.
+func showSpeed(url string) (int64, error) {
+	name := fetcher.RedactURL(url)
+	report := func(n int64, elapsed time.Duration) {
+		fmt.Fprintf(os.Stderr, "\r%s: %s, %s/s ", name, fetcher.FormatBytes(n), fetcher.FormatBytes(int64(fetcher.Speed(n, elapsed)))) // \r returns to the start of the line so the rate updates in place
 	}
-	for range os.Args[1:] { // for each URL in the command line arguments (concurrent) (wait for the results of the goroutines)
-		fmt.Println(<-ch) // receive from channel ch and print the result
+	var (
+		n   int64
+		err error
+	)
+	if batchClient != nil {
+		n, err = fetcher.FetchWithSpeedWithClient(batchClient, os.Stdout, url, 0, report)
+	} else {
+		n, err = fetcher.FetchWithSpeed(os.Stdout, url, 0, report)
 	}
-	fmt.Printf("%.2fs elapsed\n", time.Since(start).Seconds()) // print the time elapsed since the start of the timer
+	fmt.Fprintln(os.Stderr) // end the speed line
+	return n, err
 }
 
 //!-
@@ -0,0 +1,117 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// A listFlag is a flag that may be given many times, collecting each value.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, " ") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+var (
+	formFields listFlag // the -form key=value pairs, in order
+	formFiles  listFlag // the -file field=@path pairs, in order
+)
+
+func init() {
+	flag.Var(&formFields, "form", "POST each URL an application/x-www-form-urlencoded body holding `key=value`; may be repeated")
+	flag.Var(&formFiles, "file", "upload the file given as `field=@path` in a multipart/form-data POST to each URL, with any -form fields; may be repeated")
+}
+
+// formBody returns the body, and its Content-Type, that -form and -file
+// describe: multipart/form-data if any file is to be uploaded, else
+// application/x-www-form-urlencoded.
+func formBody() (body []byte, contentType string, err error) {
+	values := make(url.Values)
+	for _, f := range formFields {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" {
+			return nil, "", fmt.Errorf("-form %s: want key=value", f)
+		}
+		values.Add(k, v)
+	}
+	if len(formFiles) == 0 {
+		body, contentType = fetcher.FormBody(values)
+		return body, contentType, nil
+	}
+	files := make(map[string][]string)
+	for _, f := range formFiles {
+		k, path, ok := strings.Cut(f, "=")
+		path = strings.TrimPrefix(path, "@")
+		if !ok || k == "" || path == "" {
+			return nil, "", fmt.Errorf("-file %s: want field=@path", f)
+		}
+		files[k] = append(files[k], path) // a field may upload several files
+	}
+	return fetcher.MultipartBody(values, files)
+}
+
+// postForm posts the -form and -file body to each of the URLs in turn,
+// copying each response body to stdout and
+// reporting its status on stderr unless -quiet is set. It reports whether
+// any post failed.
+func postForm(urls []string) (failed bool) {
+	body, contentType, err := formBody()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		return true
+	}
+	headers := map[string]string{"Content-Type": contentType}
+	for _, url := range urls {
+		start := time.Now()
+		r, err := fetcher.FetchMethod(http.MethodPost, url, bytes.NewReader(body), headers)
+		if err == nil && r.Failed() {
+			err = fetcher.Errors([]fetcher.Result{*r}) // a 4xx or 5xx status
+		}
+		if r != nil {
+			os.Stdout.Write(r.Body) // the answer, even to a failed post, often says why
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			failed = true
+			continue
+		}
+		if !*quiet {
+			fmt.Fprintf(os.Stderr, "fetchall: posted %s to %s: %d %s in %s\n", fetcher.FormatBytes(int64(len(body))),
+				fetcher.RedactURL(url), r.StatusCode, http.StatusText(r.StatusCode), fetcher.FormatDuration(time.Since(start)))
+		}
+	}
+	return failed
+}
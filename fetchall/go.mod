@@ -1,3 +1,3 @@
 module mobiledatabooks.com/fetchall
 
-go 1.19
+go 1.20
@@ -0,0 +1,166 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// collectURLs returns the URLs to fetch: those listed in the file called
+// input, if it is not empty, followed by args. An argument of "-", like an
+// input of "-", stands for the URLs listed on the standard input.
+func collectURLs(input string, args []string) ([]string, error) {
+	var urls []string
+	if input != "" {
+		list, err := readURLFile(input)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, list...)
+	}
+	for _, arg := range args {
+		if arg != "-" {
+			urls = append(urls, arg)
+			continue
+		}
+		list, err := readURLFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, list...)
+	}
+	return urls, nil
+}
+
+// An annotation holds what an input entry says about its URL besides the
+// URL itself, in words such as "label=api" or "timeout=60s" written before
+// it.
+type annotation struct {
+	label   string        // the group the URL is reported in with -by-label
+	timeout time.Duration // the URL's own timeout; zero means the usual one
+}
+
+// normalizeURLs normalizes each of raw with fetcher.NormalizeURL and returns
+// the valid ones, in order, together with an error for each invalid one.
+// Each entry has its annotations split off first, and they are returned at
+// the same index of notes as the URL. If glob is set, each URL is then
+// expanded with fetcher.ExpandURL, every expansion taking its annotations.
+// Unless allowDupes is set, a URL that fetcher.SameURLKey says is the same
+// as an earlier one is dropped, and counted in dupes.
+func normalizeURLs(raw []string, glob, allowDupes bool) (urls []string, notes []annotation, dupes int, invalid []error) {
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		note, r, err := splitAnnotations(r)
+		if err != nil {
+			invalid = append(invalid, err)
+			continue
+		}
+		list := []string{r}
+		if glob {
+			if list, err = fetcher.ExpandURL(r); err != nil {
+				invalid = append(invalid, err)
+				continue
+			}
+		}
+		for _, r := range list {
+			url, err := fetcher.NormalizeURL(r)
+			if err != nil {
+				invalid = append(invalid, err)
+				continue
+			}
+			if !allowDupes {
+				key, _ := fetcher.SameURLKey(url) // cannot fail: NormalizeURL has parsed url
+				if seen[key] {
+					dupes++
+					continue
+				}
+				seen[key] = true
+			}
+			urls = append(urls, url)
+			notes = append(notes, note)
+		}
+	}
+	return urls, notes, dupes, invalid
+}
+
+// splitAnnotations splits the words "label=name" and "timeout=duration", in
+// either order, off the front of entry, and returns them with the URL that
+// follows. Other entries are returned whole, with no annotations.
+func splitAnnotations(entry string) (note annotation, url string, err error) {
+	for {
+		word, rest := entry, ""
+		if i := strings.IndexAny(entry, " \t"); i >= 0 {
+			word, rest = entry[:i], entry[i:]
+		}
+		switch {
+		case strings.HasPrefix(word, "label="):
+			note.label = strings.TrimPrefix(word, "label=")
+		case strings.HasPrefix(word, "timeout="):
+			v := strings.TrimPrefix(word, "timeout=")
+			if note.timeout, err = time.ParseDuration(v); err != nil || note.timeout <= 0 {
+				return note, "", fmt.Errorf("%s: invalid timeout %q: want a positive duration such as 60s", entry, v)
+			}
+		default:
+			return note, entry, nil // an entry with no URL left fails to normalize
+		}
+		entry = strings.TrimSpace(rest)
+	}
+}
+
+// readURLFile returns the URLs listed in the file called name, or on the
+// standard input if name is "-".
+func readURLFile(name string) ([]string, error) {
+	if name == "-" {
+		return readURLs(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readURLs(f)
+}
+
+// readURLs returns the URLs read from r, one per line, each of which may be
+// annotated by writing "label=name" or "timeout=duration" before it.
+// Surrounding spaces are trimmed, and blank lines and lines starting with #
+// are skipped.
+func readURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	input := bufio.NewScanner(r)
+	for input.Scan() {
+		line := strings.TrimSpace(input.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, input.Err()
+}
@@ -0,0 +1,65 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// streamLines fetches all the URLs at once and prints each line of their
+// bodies as it arrives, after the URL it came from if there are several,
+// until every stream ends or Ctrl-C. It reports whether any fetch failed.
+func streamLines(urls []string) (failed bool) {
+	ctx, stop := interruptible()
+	defer stop()
+	var mu sync.Mutex // serializes the output and guards failed
+	var wg sync.WaitGroup
+	for _, url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			name := fetcher.RedactURL(url)
+			_, err := fetcher.FetchLines(ctx, url, func(line string) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				if len(urls) > 1 {
+					fmt.Printf("%s: ", name)
+				}
+				fmt.Println(line)
+				return true
+			})
+			if err != nil && ctx.Err() == nil {
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+				failed = true
+			}
+		}(url)
+	}
+	wg.Wait()
+	return failed
+}
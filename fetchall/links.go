@@ -0,0 +1,64 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// checkLinks checks the URLs with fetcher.CheckLinks, printing the status
+// of each and then the URLs grouped by class; with -quiet, only the broken
+// ones. It reports whether any link is broken.
+func checkLinks(urls []string) (broken bool) {
+	statuses := fetcher.CheckLinks(urls)
+	groups := make(map[fetcher.LinkClass][]fetcher.LinkStatus)
+	for _, s := range statuses {
+		groups[s.Class] = append(groups[s.Class], s)
+		broken = broken || s.Class.Broken()
+	}
+
+	classes := []fetcher.LinkClass{fetcher.LinkOK, fetcher.LinkRedirect, fetcher.LinkClientError, fetcher.LinkServerError, fetcher.LinkFailed}
+	for _, c := range classes {
+		if len(groups[c]) == 0 || *quiet && !c.Broken() {
+			continue
+		}
+		fmt.Printf("%s (%d):\n", c, len(groups[c]))
+		for _, s := range groups[c] {
+			s.URL = fetcher.RedactURL(s.URL) // never print a password given in the URL
+			switch {
+			case s.Err != nil:
+				fmt.Printf("  %s: %v\n", s.URL, s.Err)
+			case s.Location != "":
+				fmt.Printf("  %d %s -> %s\n", s.StatusCode, s.URL, s.Location)
+			default:
+				fmt.Printf("  %d %s\n", s.StatusCode, s.URL)
+			}
+		}
+	}
+	fmt.Printf("%d ok, %d redirect, %d client error, %d server error, %d failed\n",
+		len(groups[fetcher.LinkOK]), len(groups[fetcher.LinkRedirect]), len(groups[fetcher.LinkClientError]),
+		len(groups[fetcher.LinkServerError]), len(groups[fetcher.LinkFailed]))
+	return broken
+}
@@ -0,0 +1,79 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// mirror saves each of the URLs under the -mirror directory, at a path that
+// follows the URL, printing where each went. With -accept, content of other
+// types is reported as skipped and not saved. With -save-headers, each
+// response header is saved beside its body, and with -gzip-out each body
+// is compressed. With -manifest, index.json records every fetch once they
+// are all done, together with the other URLs of the -replay manifest, if
+// any. It reports whether any fetch failed.
+func mirror(urls []string) (failed bool) {
+	opts := fetcher.MirrorOptions{Client: batchClient, Headers: *saveHeaders, Gzip: *gzipOut}
+	if *acceptTypes != "" {
+		opts.Accept = strings.Split(*acceptTypes, ",")
+	}
+	if *manifest {
+		opts.Manifest = new(fetcher.Manifest)
+	}
+	for _, rawURL := range urls {
+		name, n, err := fetcher.FetchToMirrorWith(rawURL, *mirrorDir, opts)
+		var cte *fetcher.ContentTypeError
+		switch {
+		case errors.As(err, &cte):
+			fmt.Printf("skipped  %s  %s\n", cte.ContentType, fetcher.RedactURL(rawURL)) // not wanted, but not a failure either
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			failed = true
+		default:
+			fmt.Printf("saved  %s  %s  %s\n", name, fetcher.FormatBytes(n), fetcher.RedactURL(rawURL)) // the size before any -gzip-out compression
+		}
+	}
+	if opts.Manifest != nil {
+		name := filepath.Join(*mirrorDir, "index.json")
+		if *replay != "" {
+			opts.Manifest.Merge(replayed) // keep what the earlier run recorded of the URLs not fetched again
+		}
+		err := os.MkdirAll(*mirrorDir, 0o755) // every fetch may have failed
+		if err == nil {
+			err = opts.Manifest.WriteFile(name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			return true
+		}
+		fmt.Printf("wrote  %s\n", name)
+	}
+	return failed
+}
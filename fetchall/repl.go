@@ -0,0 +1,178 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// replHelp describes the commands repl understands.
+const replHelp = `enter a URL, or a pattern or list entry as in an -i file, to fetch it, or:
+  :header Name: value   send the header with every request (an empty value removes it)
+  :timeout duration     set the timeout of each request, such as 5s (0 restores the default)
+  :c n                  fetch at most n URLs of a pattern at a time (0 means no limit)
+  :show                 print the settings
+  :help                 print this message
+  :quit                 stop, as end of input does`
+
+// A session holds what the commands typed into repl have set, on top of the
+// flags.
+type session struct {
+	headers     map[string]string
+	timeout     time.Duration
+	concurrency int
+}
+
+// repl reads lines from in until end of input or :quit, fetching each URL
+// entered and printing its result as printResult does, and running each
+// command, which changes the headers, timeout or concurrency of the fetches
+// after it. The prompt is printed only when in is a terminal. It reports
+// whether the last fetch failed.
+func repl(in *os.File) (failed bool) {
+	s := &session{headers: make(map[string]string), concurrency: *concurrency}
+	prompt := ""
+	if fi, err := in.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		prompt = "fetchall> "
+		fmt.Println(`fetchall: type a URL to fetch it, or :help`)
+	}
+	sc := bufio.NewScanner(in)
+	for fmt.Print(prompt); sc.Scan(); fmt.Print(prompt) {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line == ":quit" || line == ":q":
+			return failed
+		case strings.HasPrefix(line, ":"):
+			if err := s.command(os.Stdout, line); err != nil {
+				fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+			}
+		default:
+			failed = s.fetch(line)
+		}
+	}
+	if prompt != "" {
+		fmt.Println() // end the line holding the last prompt
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		return true
+	}
+	return failed
+}
+
+// command runs the command in line, which starts with a colon, writing
+// anything it prints to w.
+func (s *session) command(w io.Writer, line string) error {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":header", ":h":
+		k, v, ok := strings.Cut(arg, ":")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			return fmt.Errorf("%s: want Name: value", line)
+		}
+		if v == "" {
+			delete(s.headers, k)
+		} else {
+			s.headers[k] = v
+		}
+	case ":timeout", ":t":
+		d, err := time.ParseDuration(arg)
+		if err != nil || d < 0 {
+			return fmt.Errorf("%s: want a duration such as 5s", line)
+		}
+		s.timeout = d
+	case ":c":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: want a number of fetches", line)
+		}
+		s.concurrency = n
+	case ":show":
+		s.show(w)
+	case ":help":
+		fmt.Fprintln(w, replHelp)
+	default:
+		return fmt.Errorf("unknown command %s; try :help", name)
+	}
+	return nil
+}
+
+// show writes the settings of s to w.
+func (s *session) show(w io.Writer) {
+	timeout, c := "default", "no limit"
+	if s.timeout > 0 {
+		timeout = fetcher.FormatDuration(s.timeout)
+	}
+	if s.concurrency > 0 {
+		c = strconv.Itoa(s.concurrency)
+	}
+	fmt.Fprintf(w, "timeout %s, concurrency %s\n", timeout, c)
+	names := make([]string, 0, len(s.headers))
+	for k := range s.headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(w, "%s: %s\n", k, s.headers[k])
+	}
+}
+
+// fetch fetches the URLs entry names, as a line of an -i file would, with
+// the settings of s, printing each result and how long they took. It
+// reports whether any failed.
+func (s *session) fetch(entry string) (failed bool) {
+	urls, notes, _, invalid := normalizeURLs([]string{entry}, !*globOff, *allowDupes)
+	for _, err := range invalid {
+		fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+	}
+	if len(urls) == 0 {
+		return len(invalid) > 0
+	}
+	for i := range notes {
+		if notes[i].timeout == 0 {
+			notes[i].timeout = s.timeout // an annotation on the entry wins
+		}
+	}
+	start := time.Now()
+	results := fetchAllWith(urls, notes, printResult,
+		func(ctx context.Context, urls []string, opts fetcher.BatchOptions) []fetcher.Result {
+			opts.Concurrency = s.concurrency
+			opts.Headers = s.headers // they win over the -config ones, which batchClient adds
+			return fetcher.FetchBatchContext(ctx, urls, opts)
+		})
+	sum := fetcher.Summarize(results)
+	fmt.Printf("%d succeeded, %d failed in %s\n", sum.Succeeded, sum.Failed, fetcher.FormatDuration(time.Since(start)))
+	return sum.Failed > 0
+}
@@ -0,0 +1,52 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// replayed holds the entries of the -replay manifest, read by replayURLs.
+var replayed []fetcher.ManifestEntry
+
+// replayURLs returns the URLs that failed in the -replay manifest, so that
+// they alone are fetched again. It exits if the manifest cannot be read.
+func replayURLs() []string {
+	entries, err := fetcher.ReadManifest(*replay)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetchall: -replay: %v\n", err)
+		os.Exit(1)
+	}
+	replayed = entries
+	var urls []string
+	for _, e := range entries {
+		if e.Failed() {
+			urls = append(urls, e.URL)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "fetchall: replaying %d of the %d URLs in %s\n", len(urls), len(entries), *replay)
+	return urls
+}
@@ -0,0 +1,56 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// sitemapPages returns the URLs of the pages listed by the sitemaps at urls,
+// which main fetches in their place, normalized and without duplicates
+// unless -allow-dupes is set. Sitemaps that cannot be read and pages with
+// invalid URLs are reported on stderr and skipped; if no page is left, it
+// exits.
+func sitemapPages(urls []string) []string {
+	var found []string
+	for _, url := range urls {
+		pages, err := fetcher.FetchSitemap(url)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetchall: %v\n", err)
+		}
+		found = append(found, pages...)
+	}
+	pages, _, _, invalid := normalizeURLs(found, false, *allowDupes) // the sitemaps list URLs, not patterns
+	for _, err := range invalid {
+		fmt.Fprintf(os.Stderr, "fetchall: sitemap entry: %v\n", err)
+	}
+	if len(pages) == 0 {
+		fmt.Fprintln(os.Stderr, "fetchall: the sitemaps list no pages to fetch")
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "fetchall: %d pages listed\n", len(pages))
+	return pages
+}
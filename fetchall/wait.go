@@ -0,0 +1,69 @@
+package main
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mobiledatabooks/go-fetch/fetcher"
+)
+
+// waitForURLs fetches each of the URLs in turn every -interval until it
+// answers with a 2xx status, giving up when -deadline, which covers them
+// all, passes or on Ctrl-C. It prints how long each took to come up and
+// reports whether any never did.
+func waitForURLs(urls []string) (failed bool) {
+	ctx, stop := interruptible()
+	defer stop()
+	if *deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *deadline)
+		defer cancel()
+	}
+	for _, url := range urls {
+		start := time.Now()
+		r, attempts, err := fetcher.WaitUntilOK(ctx, batchClient, url, *interval)
+		name := fetcher.RedactURL(url)
+		if err != nil {
+			var last string // why the last attempt failed
+			switch {
+			case r.Err != nil && !errors.Is(r.Err, err):
+				last = r.Err.Error()
+			case r.StatusCode != 0:
+				last = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+			default:
+				last = err.Error() // the only attempt was cut short
+			}
+			fmt.Fprintf(os.Stderr, "fetchall: gave up on %s after %d attempts in %s: %s\n",
+				name, attempts, fetcher.FormatDuration(time.Since(start)), last)
+			return true // the deadline has passed for the rest too
+		}
+		fmt.Printf("%d  %s  up after %d attempts in %s\n", r.StatusCode, name, attempts, fetcher.FormatDuration(time.Since(start)))
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// A ContentTypeError reports that the content found at URL was not saved
+// because its type was not one of those accepted.
+type ContentTypeError struct {
+	URL         string
+	ContentType string // the Content-Type header of the response
+}
+
+func (e *ContentTypeError) Error() string {
+	ct := e.ContentType
+	if ct == "" {
+		ct = "no type"
+	}
+	return fmt.Sprintf("fetch %s: skipped %s", RedactURL(e.URL), ct)
+}
+
+// FetchToMirrorAccept is like FetchToMirror but saves the content only if
+// its Content-Type is one of accept, such as "text/html" or "image/*", and
+// otherwise returns a *ContentTypeError. A HEAD request is made first, so
+// that unwanted bodies are not downloaded at all when the server reports
+// the type; the type of the GET response is checked too.
+func FetchToMirrorAccept(rawURL, root string, accept []string) (string, error) {
+	name, _, err := FetchToMirrorWith(rawURL, root, MirrorOptions{Accept: accept})
+	return name, err
+}
+
+// acceptsType reports whether the media type of the Content-Type value ct
+// matches one of the patterns in accept: a type such as "text/html", a
+// "type/*" wildcard, or "*/*". A missing type counts as
+// application/octet-stream, as HTTP says it should.
+func acceptsType(accept []string, ct string) bool {
+	mt := "application/octet-stream"
+	if ct != "" {
+		var err error
+		if mt, _, err = mime.ParseMediaType(ct); err != nil {
+			return false
+		}
+	}
+	for _, pattern := range accept {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == mt, pattern == "*/*":
+			return true
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
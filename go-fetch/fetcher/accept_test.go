@@ -0,0 +1,87 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAcceptsType(t *testing.T) {
+	tests := []struct {
+		accept []string
+		ct     string
+		want   bool
+	}{
+		{[]string{"text/html"}, "text/html; charset=utf-8", true},
+		{[]string{"text/html"}, "TEXT/HTML", true},
+		{[]string{"text/html"}, "text/plain", false},
+		{[]string{" Image/* "}, "image/png", true},
+		{[]string{"image/*"}, "imagery/png", false},
+		{[]string{"*/*"}, "video/mp4", true},
+		{[]string{"application/octet-stream"}, "", true},
+		{[]string{"text/html"}, "", false},
+		{[]string{"*/*"}, "text/html; charset=\"", false},
+		{nil, "text/html", false},
+	}
+	for _, tt := range tests {
+		if got := acceptsType(tt.accept, tt.ct); got != tt.want {
+			t.Errorf("acceptsType(%q, %q) = %v, want %v", tt.accept, tt.ct, got, tt.want)
+		}
+	}
+}
+
+func TestFetchToMirrorAccept(t *testing.T) {
+	var gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+		if strings.HasSuffix(r.URL.Path, ".png") {
+			w.Header().Set("Content-Type", "image/png")
+		} else {
+			w.Header().Set("Content-Type", "text/html")
+		}
+		w.Write([]byte("content"))
+	}))
+	defer srv.Close()
+	root := t.TempDir()
+
+	_, err := FetchToMirrorAccept(srv.URL+"/pic.png", root, []string{"text/html"})
+	var ce *ContentTypeError
+	if !errors.As(err, &ce) || ce.ContentType != "image/png" || gets.Load() != 0 {
+		t.Errorf("FetchToMirrorAccept of an image = %v after %d GETs; want a *ContentTypeError and no GET", err, gets.Load())
+	}
+	name, err := FetchToMirrorAccept(srv.URL+"/page", root, []string{"text/html"})
+	if b, _ := os.ReadFile(name); err != nil || string(b) != "content" {
+		t.Errorf("FetchToMirrorAccept of a page = %q, %v; want it saved", name, err)
+	}
+	if msg := (&ContentTypeError{URL: "http://x/", ContentType: ""}).Error(); msg != "fetch http://x/: skipped no type" {
+		t.Errorf("ContentTypeError with no type = %q", msg)
+	}
+}
@@ -0,0 +1,288 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FetchAll fetches urls with at most concurrency fetches in flight at once
+// and returns one Result per URL, in the same order as urls. If concurrency
+// is zero or negative every URL is fetched at once, as FetchConcurrent does.
+func FetchAll(urls []string, concurrency int) []Result {
+	return FetchAllWithClient(clientWithTimeout(DefaultTimeout), urls, concurrency)
+}
+
+// FetchAllWithClient is like FetchAll but sends every request with client,
+// such as one returned by NewClient, so that they share its connections.
+func FetchAllWithClient(client *http.Client, urls []string, concurrency int) []Result {
+	return FetchBatch(urls, BatchOptions{Client: client, Concurrency: concurrency})
+}
+
+// A ProgressFunc is told, after each fetch of a batch completes, how many of
+// the total have completed so far. Calls are never concurrent, and done
+// increases by one with each call.
+type ProgressFunc func(done, total int)
+
+// FetchAllWithProgress is like FetchAll but calls progress after each fetch
+// completes.
+func FetchAllWithProgress(urls []string, concurrency int, progress ProgressFunc) []Result {
+	return FetchBatch(urls, BatchOptions{Concurrency: concurrency, Progress: progress})
+}
+
+// BatchOptions configures FetchBatch. The zero value fetches every URL at
+// once using the package's shared client.
+type BatchOptions struct {
+	Client      *http.Client      // nil means a client with DefaultTimeout sharing the package's connections
+	Concurrency int               // maximum fetches in flight; <= 0 means no limit
+	PerHost     int               // maximum fetches in flight to any one host; <= 0 means no limit
+	Progress    ProgressFunc      // if non-nil, called after each fetch
+	Limiter     *Limiter          // if non-nil, every fetch waits for it before starting
+	Checksum    bool              // compute the SHA-256 of each body into Result.SHA256
+	Method      string            // request method; empty means GET, and HEAD fetches no bodies
+	KeepBody    bool              // keep each body in Result.Body rather than discarding it
+	Headers     map[string]string // set on every request, as FetchWithHeaders does
+	Jitter      time.Duration     // each fetch first waits a random time up to Jitter, so that they do not start in bursts
+
+	// Retries is how many times a failed fetch is retried, after a backoff
+	// from RetryDelay, as FetchWithRetryPolicy retries it: only errors and
+	// statuses that may pass are retried, and only for idempotent methods.
+	// RetryBudget, if not nil, bounds the retries of the whole batch, so
+	// that a widespread outage does not multiply the load by Retries.
+	Retries     int
+	RetryDelay  time.Duration
+	RetryBudget *RetryBudget
+
+	// OnResult, if non-nil, is called with each Result and the index of its
+	// URL as soon as the fetch completes. Calls are never concurrent unless
+	// ConcurrentOnResult is set, in which case OnResult must do its own
+	// locking. If Ordered is set, results that complete early are held back
+	// so that OnResult sees them in input order, one at a time.
+	OnResult           func(i int, r Result)
+	Ordered            bool
+	ConcurrentOnResult bool
+
+	// Check, if non-nil, is called with the body of each response that
+	// does not have a 4xx or 5xx status; if it returns an error, the fetch
+	// fails with a *CheckError. Each body is held in memory to be checked.
+	// BodyContains and BodyMatches return common checks.
+	Check func(body []byte) error
+
+	// Labels, if not nil, labels each URL with the string of the same
+	// index, such as the name of the service it belongs to. The label is
+	// copied into Result.Label, and Summary.ByLabel groups the results by
+	// it. URLs beyond the end of Labels have no label.
+	Labels []string
+
+	// Timeouts, if not nil, gives each URL whose entry of the same index is
+	// positive that timeout in place of the client's, so that known-slow
+	// URLs can be allowed longer without slowing the failure of the rest.
+	Timeouts []time.Duration
+
+	// FailFast stops the batch as soon as a fetch fails, with an error or
+	// a 4xx or 5xx status: fetches in progress are cancelled and those yet
+	// to start are not started, and their Results have an Err wrapping
+	// context.Canceled.
+	FailFast bool
+}
+
+// FetchAllFunc fetches urls, all at once, and calls fn with each Result as
+// its fetch completes. The calls are never concurrent. Unlike FetchAll it
+// keeps no results, so that a huge list can be fetched without holding
+// every Result in memory; use StreamBatch for more control.
+func FetchAllFunc(urls []string, fn func(Result)) {
+	StreamBatch(urls, BatchOptions{OnResult: func(_ int, r Result) { fn(r) }})
+}
+
+// StreamBatch is like FetchBatch but returns no results: they are seen only
+// by opts.OnResult, and are not kept once it returns.
+func StreamBatch(urls []string, opts BatchOptions) {
+	stream(context.Background(), urls, opts)
+}
+
+// FetchBatch fetches urls as opts describes and returns one Result per URL,
+// in the same order as urls.
+func FetchBatch(urls []string, opts BatchOptions) []Result {
+	return fetchAll(context.Background(), urls, opts)
+}
+
+// FetchBatchError is like FetchBatch but also returns an error joining,
+// with errors.Join, the errors of every fetch that failed, in input order,
+// or nil if none did; see Errors. With opts.FailFast it instead returns
+// only the error of the first fetch to fail, as soon as the fetches
+// cancelled by that failure have stopped.
+func FetchBatchError(urls []string, opts BatchOptions) ([]Result, error) {
+	results := make([]Result, len(urls))
+	first := stream(context.Background(), urls, collect(results, opts))
+	if opts.FailFast {
+		return results, first
+	}
+	return results, Errors(results)
+}
+
+// Errors joins, with errors.Join, the errors of the results that failed,
+// such as those returned by FetchAll, so that every failure of a batch can
+// be reported or inspected with errors.Is and errors.As. A 4xx or 5xx
+// response counts as a *StatusError. Each error names the URL it came
+// from. Errors returns nil if no result failed.
+func Errors(results []Result) error {
+	var errs []error
+	for _, r := range results {
+		if err := r.err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FetchBatchContext is like FetchBatch but stops when ctx is done: fetches
+// in progress are cancelled, those yet to start are not started, and every
+// such URL's Result has an Err naming the URL and wrapping ctx.Err(), for
+// errors.Is to find. Results that completed before then are returned as
+// usual.
+func FetchBatchContext(ctx context.Context, urls []string, opts BatchOptions) []Result {
+	return fetchAll(ctx, urls, opts)
+}
+
+// fetchAll is FetchBatch with a context; cancelling ctx aborts the fetches
+// that are still in progress or waiting to start.
+func fetchAll(ctx context.Context, urls []string, opts BatchOptions) []Result {
+	results := make([]Result, len(urls))
+	stream(ctx, urls, collect(results, opts))
+	return results
+}
+
+// collect returns opts with OnResult changed to store each Result in its
+// place in results before calling the original OnResult, if any.
+func collect(results []Result, opts BatchOptions) BatchOptions {
+	each := opts.OnResult
+	opts.OnResult = func(i int, r Result) {
+		results[i] = r // each index is written once, so concurrent calls are safe
+		if each != nil {
+			each(i, r)
+		}
+	}
+	return opts
+}
+
+// forURL returns the options for fetching the i'th URL: opts itself, or a
+// copy whose client has the URL's own timeout from opts.Timeouts.
+func (opts *BatchOptions) forURL(i int) *BatchOptions {
+	if i >= len(opts.Timeouts) || opts.Timeouts[i] <= 0 {
+		return opts
+	}
+	o := *opts
+	client := *opts.Client // sharing its transport, and so its connections
+	client.Timeout = opts.Timeouts[i]
+	o.Client = &client
+	return &o
+}
+
+// stream fetches urls as opts describes, handing each Result to
+// opts.OnResult and keeping none. It returns the error of the first fetch
+// to fail.
+func stream(ctx context.Context, urls []string, opts BatchOptions) error {
+	if opts.Client == nil {
+		opts.Client = clientWithTimeout(DefaultTimeout)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var tokens chan struct{} // counting semaphore; nil means unbounded
+	if opts.Concurrency > 0 {
+		tokens = make(chan struct{}, opts.Concurrency)
+	}
+	hosts := newHostSemaphores(opts.PerHost)
+	var (
+		mu       sync.Mutex // guards done, next, pending and firstErr, and serializes the callbacks
+		done     int
+		firstErr error          // of the first fetch to fail
+		next     int            // index of the next result OnResult should see when Ordered
+		pending  map[int]Result // completed results waiting for an earlier one when Ordered
+	)
+	if opts.Ordered {
+		pending = make(map[int]Result)
+	}
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			if release, ok := hosts.acquire(ctx, url); ok { // before the global token, so no token is held while waiting on a busy host
+				defer release()
+			}
+			if tokens != nil {
+				select {
+				case tokens <- struct{}{}: // acquire a token
+					defer func() { <-tokens }()
+				case <-ctx.Done(): // give up waiting; the check below records why
+				}
+			}
+			var r Result
+			if start := time.Now(); ctx.Err() != nil || jitter(ctx, opts.Jitter) != nil || opts.Limiter.Wait(ctx) != nil {
+				r = Result{URL: url, Err: ctx.Err()}
+				r.setTimes(start)
+			} else {
+				r = fetchRetrying(ctx, url, opts.forURL(i))
+			}
+			if r.Err != nil && !namesURL(r.Err) {
+				r.Err = r.err() // so that "context canceled" says which fetch it stopped
+			}
+			if i < len(opts.Labels) {
+				r.Label = opts.Labels[i]
+			}
+			if opts.OnResult != nil && opts.ConcurrentOnResult && !opts.Ordered {
+				opts.OnResult(i, r) // outside the lock, as the caller asked
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Failed() && firstErr == nil {
+				firstErr = r.err()
+				if opts.FailFast {
+					cancel() // stop the rest of the batch
+				}
+			}
+			switch {
+			case opts.OnResult == nil, opts.ConcurrentOnResult && !opts.Ordered:
+			case !opts.Ordered:
+				opts.OnResult(i, r)
+			default:
+				pending[i] = r
+				for r, ok := pending[next]; ok; r, ok = pending[next] { // flush every result now in sequence
+					delete(pending, next)
+					opts.OnResult(next, r)
+					next++
+				}
+			}
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, len(urls))
+			}
+		}(i, url)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,305 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// inFlightServer returns a server that answers after a short delay and
+// records in *peak the most requests it has had in progress at once.
+func inFlightServer(peak *int) *httptest.Server {
+	var mu sync.Mutex
+	n := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		n++
+		if n > *peak {
+			*peak = n
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		n--
+		mu.Unlock()
+		fmt.Fprint(w, r.URL.Path)
+	}))
+}
+
+func TestFetchAllConcurrency(t *testing.T) {
+	peak := 0
+	srv := inFlightServer(&peak)
+	defer srv.Close()
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	results := FetchAll(urls, 3)
+	if len(results) != len(urls) {
+		t.Fatalf("FetchAll returned %d results for %d URLs", len(results), len(urls))
+	}
+	for i, r := range results {
+		if r.URL != urls[i] || r.Err != nil || r.StatusCode != http.StatusOK || r.Bytes != int64(len(fmt.Sprint("/", i))) {
+			t.Errorf("result %d = %s %d %v, %d bytes; want %s 200 OK", i, r.URL, r.StatusCode, r.Err, r.Bytes, urls[i])
+		}
+	}
+	if peak > 3 || peak == 0 {
+		t.Errorf("%d fetches in flight at once, want at most 3", peak)
+	}
+}
+
+func TestFetchBatchPerHost(t *testing.T) {
+	var peakA, peakB int
+	a, b := inFlightServer(&peakA), inFlightServer(&peakB)
+	defer a.Close()
+	defer b.Close()
+	var urls []string
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", a.URL, i), fmt.Sprintf("%s/%d", b.URL, i))
+	}
+	for _, r := range FetchBatch(urls, BatchOptions{PerHost: 2}) {
+		if r.Failed() {
+			t.Errorf("%s: status %d, err %v", r.URL, r.StatusCode, r.Err)
+		}
+	}
+	if peakA != 2 || peakB != 2 {
+		t.Errorf("%d and %d fetches in flight to each host at once, want 2", peakA, peakB)
+	}
+}
+
+func TestFetchBatchContextCancelNamesURL(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select { // until the test ends or the request is cancelled
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel) // with one fetch in flight and the other waiting for it
+	urls := []string{srv.URL + "/a", srv.URL + "/b"}
+	results := FetchBatchContext(ctx, urls, BatchOptions{Concurrency: 1})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: err %v, want context.Canceled", r.URL, r.Err)
+			continue
+		}
+		if !strings.Contains(r.Err.Error(), r.URL) {
+			t.Errorf("%s: error %q does not name the URL", r.URL, r.Err)
+		}
+	}
+}
+
+func TestFetchAllWithProgress(t *testing.T) {
+	peak := 0
+	srv := inFlightServer(&peak)
+	defer srv.Close()
+	urls := []string{srv.URL + "/a", srv.URL + "/b", srv.URL + "/c", srv.URL + "/d"}
+	var calls []int
+	FetchAllWithProgress(urls, 2, func(done, total int) {
+		if total != len(urls) {
+			t.Errorf("progress total = %d, want %d", total, len(urls))
+		}
+		calls = append(calls, done) // calls are never concurrent
+	})
+	if fmt.Sprint(calls) != "[1 2 3 4]" {
+		t.Errorf("progress counted %v, want [1 2 3 4]", calls)
+	}
+}
+
+func TestFetchBatchChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+	r := FetchBatch([]string{srv.URL}, BatchOptions{Checksum: true})[0]
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; r.Err != nil || r.SHA256 != want {
+		t.Errorf("SHA256 = %q, %v; want %s, the SHA-256 of hello", r.SHA256, r.Err, want)
+	}
+	if r := FetchBatch([]string{srv.URL}, BatchOptions{})[0]; r.SHA256 != "" {
+		t.Errorf("SHA256 = %q without Checksum, want none", r.SHA256)
+	}
+}
+
+func TestFetchBatchOrdered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, _ := time.ParseDuration(strings.TrimPrefix(r.URL.Path, "/") + "ms")
+		time.Sleep(ms) // the first URLs finish last
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/80", srv.URL + "/40", srv.URL + "/0"}
+	var order []int
+	FetchBatch(urls, BatchOptions{Ordered: true, OnResult: func(i int, r Result) {
+		if r.URL != urls[i] {
+			t.Errorf("OnResult(%d) given the result for %s", i, r.URL)
+		}
+		order = append(order, i)
+	}})
+	if fmt.Sprint(order) != "[0 1 2]" {
+		t.Errorf("Ordered: OnResult saw %v, want [0 1 2]", order)
+	}
+}
+
+func TestFetchAllFunc(t *testing.T) {
+	peak := 0
+	srv := inFlightServer(&peak)
+	defer srv.Close()
+	urls := []string{srv.URL + "/a", srv.URL + "/b", srv.URL + "/c", srv.URL + "/d"}
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	FetchAllFunc(urls, func(r Result) {
+		if !mu.TryLock() {
+			t.Errorf("fn called concurrently for %s", r.URL)
+			return
+		}
+		defer mu.Unlock()
+		if r.Failed() || seen[r.URL] {
+			t.Errorf("%s: status %d, err %v, seen before %v", r.URL, r.StatusCode, r.Err, seen[r.URL])
+		}
+		seen[r.URL] = true
+		time.Sleep(5 * time.Millisecond) // so that a concurrent call would overlap
+	})
+	if len(seen) != len(urls) || peak < 2 {
+		t.Errorf("fn saw %d of %d URLs, %d fetched at once; want all of them, concurrently", len(seen), len(urls), peak)
+	}
+}
+
+func TestFetchBatchFailFast(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		<-r.Context().Done() // until the batch gives up on it
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/slow", srv.URL + "/fail", srv.URL + "/stuck"}
+
+	start := time.Now()
+	results, err := FetchBatchError(urls, BatchOptions{FailFast: true})
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("FetchBatchError = %v, want the 500 *StatusError of /fail", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("FailFast took %v to stop the batch", d)
+	}
+	for _, i := range []int{0, 2} {
+		if r := results[i]; !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: err %v, want context.Canceled", r.URL, r.Err)
+		}
+	}
+}
+
+func TestFetchBatchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/ok", srv.URL + "/missing", "http://127.0.0.1:1/refused"}
+
+	results, err := FetchBatchError(urls, BatchOptions{})
+	if len(results) != len(urls) || results[0].Failed() {
+		t.Fatalf("FetchBatchError returned %d results, first %+v", len(results), results[0])
+	}
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusNotFound || se.URL != urls[1] {
+		t.Errorf("FetchBatchError err = %v, want a 404 *StatusError for %s", err, urls[1])
+	}
+	msg := fmt.Sprint(err)
+	if strings.Contains(msg, urls[0]) || !strings.Contains(msg, urls[1]) || !strings.Contains(msg, urls[2]) || strings.Index(msg, urls[1]) > strings.Index(msg, urls[2]) {
+		t.Errorf("FetchBatchError err = %q, want the two failures in input order, each naming its URL", msg)
+	}
+	if err := Errors(results[:1]); err != nil {
+		t.Errorf("Errors of a success = %v, want nil", err)
+	}
+	if err := Errors([]Result{{URL: "http://x/", Err: errors.New("boom")}}); err == nil || err.Error() != "fetch http://x/: boom" {
+		t.Errorf("Errors = %v, want the error prefixed with its URL", err)
+	}
+}
+
+func TestFetchBatchJitter(t *testing.T) {
+	var requests []time.Time
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	var urls []string
+	for i := 0; i < 8; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	FetchBatch(urls, BatchOptions{Jitter: 100 * time.Millisecond})
+	if len(requests) != len(urls) {
+		t.Fatalf("%d requests for %d URLs", len(requests), len(urls))
+	}
+	first, last := requests[0], requests[0]
+	for _, at := range requests {
+		if at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	if last.Sub(first) < 10*time.Millisecond {
+		t.Errorf("requests arrived within %v, want them spread over up to 100ms", last.Sub(first))
+	}
+}
+
+func TestFetchBatchTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/patient", srv.URL + "/default", srv.URL + "/beyond"}
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+
+	results := FetchBatch(urls, BatchOptions{Client: client, Timeouts: []time.Duration{time.Second, 0}})
+	if r := results[0]; r.Failed() {
+		t.Errorf("%s with a 1s timeout: status %d, err %v; want success", r.URL, r.StatusCode, r.Err)
+	}
+	for _, r := range results[1:] {
+		var te *TimeoutError
+		if !errors.As(r.Err, &te) {
+			t.Errorf("%s with the client's 20ms timeout: err %v, want a *TimeoutError", r.URL, r.Err)
+		}
+	}
+	if client.Timeout != 20*time.Millisecond {
+		t.Errorf("client timeout changed to %v", client.Timeout)
+	}
+}
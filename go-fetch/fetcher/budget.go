@@ -0,0 +1,104 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// A RetryBudget bounds the retries made by all the fetches of a batch, or
+// of several batches sharing it. It is safe for concurrent use.
+type RetryBudget struct {
+	limit   int64
+	used    atomic.Int64
+	refused atomic.Int64
+}
+
+// NewRetryBudget returns a budget allowing n retries in all.
+func NewRetryBudget(n int) *RetryBudget {
+	return &RetryBudget{limit: int64(n)}
+}
+
+// take reports whether a retry may be made, counting it against b if so,
+// or as refused if not. A nil budget allows every retry.
+func (b *RetryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	for {
+		n := b.used.Load()
+		if n >= b.limit {
+			b.refused.Add(1)
+			return false
+		}
+		if b.used.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// Limit returns the number of retries b allows.
+func (b *RetryBudget) Limit() int { return int(b.limit) }
+
+// Used returns the number of retries made against b so far.
+func (b *RetryBudget) Used() int { return int(b.used.Load()) }
+
+// Refused returns the number of retries b has refused because it was spent.
+func (b *RetryBudget) Refused() int { return int(b.refused.Load()) }
+
+// fetchRetrying is fetchResult retrying a failed fetch as opts.Retries,
+// opts.RetryDelay and opts.RetryBudget say. Each retry waits for
+// opts.Limiter, like the first attempt. The Result is that of the last
+// attempt.
+func fetchRetrying(ctx context.Context, url string, opts *BatchOptions) Result {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	p := RetryPolicy{BaseDelay: opts.RetryDelay}
+	for attempt := 1; ; attempt++ {
+		r := fetchResult(ctx, url, opts)
+		if !r.Failed() || attempt > opts.Retries || !idempotent(method) {
+			return r
+		}
+		err := r.err()
+		if r.Err == nil {
+			err = statusError(url, &http.Response{StatusCode: r.StatusCode, Header: r.Header}) // with any Retry-After
+		}
+		if !retryable(err) || !opts.RetryBudget.take() {
+			return r
+		}
+		logf(LevelInfo, "retrying %s: attempt %d failed: %v", RedactURL(url), attempt, err)
+		select {
+		case <-time.After(p.delay(err, attempt)):
+		case <-ctx.Done():
+			return r
+		}
+		if opts.Limiter.Wait(ctx) != nil {
+			return r
+		}
+	}
+}
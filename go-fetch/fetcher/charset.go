@@ -0,0 +1,126 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ErrUnsupportedCharset is returned, wrapped, by DecodeCharset for a
+// charset it cannot decode.
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+// charsetSniffLen is how far into a body DetectCharset looks for a <meta>
+// tag declaring its charset, as browsers do.
+const charsetSniffLen = 1024
+
+// DetectCharset returns the charset, in lower case, of a body served with
+// the Content-Type contentType: the one its charset parameter names, or
+// failing that the one a byte order mark at the start of body shows, or
+// for HTML, the one declared by a <meta> tag near its start. It returns ""
+// if none of them says.
+func DetectCharset(contentType string, body []byte) string {
+	mt, params, err := mime.ParseMediaType(contentType)
+	if cs := params["charset"]; err == nil && cs != "" {
+		return strings.ToLower(strings.Trim(cs, `"' `))
+	}
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte("\xff\xfe")):
+		return "utf-16le"
+	case bytes.HasPrefix(body, []byte("\xfe\xff")):
+		return "utf-16be"
+	}
+	if contentType == "" || mt == "text/html" || mt == "application/xhtml+xml" {
+		return metaCharset(body)
+	}
+	return ""
+}
+
+// metaCharset returns the charset declared by a <meta charset> or <meta
+// http-equiv="Content-Type"> tag near the start of the HTML document body.
+func metaCharset(body []byte) string {
+	if len(body) > charsetSniffLen {
+		body = body[:charsetSniffLen]
+	}
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		name, attrs, ok := nextTag(z)
+		if !ok {
+			return ""
+		}
+		if name != "meta" {
+			continue
+		}
+		if cs := attrs["charset"]; cs != "" {
+			return strings.ToLower(strings.TrimSpace(cs))
+		}
+		if strings.EqualFold(attrs["http-equiv"], "content-type") {
+			if _, params, err := mime.ParseMediaType(attrs["content"]); err == nil && params["charset"] != "" {
+				return strings.ToLower(params["charset"])
+			}
+		}
+	}
+}
+
+// DecodeCharset returns body, which is encoded in charset, transcoded to
+// UTF-8. It decodes the charsets of the WHATWG Encoding Standard, by any of
+// their labels, which is every one that browsers decode: among them UTF-16,
+// Shift_JIS, GBK, KOI8-R and the ISO-8859 and windows- code pages; for any
+// other the error wraps ErrUnsupportedCharset. ISO-8859-1 and US-ASCII are
+// decoded as windows-1252, as browsers decode them. A byte order mark is
+// dropped, and bytes that are not valid in charset become U+FFFD. Plain
+// "utf-16" is decoded in the byte order its byte order mark gives, or as
+// big-endian if it has none.
+func DecodeCharset(body []byte, charset string) ([]byte, error) {
+	var enc encoding.Encoding
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "unicode-1-1-utf-8":
+		return bytes.ToValidUTF8(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), []byte("�")), nil
+	case "utf-16":
+		// The label gives no byte order: follow the byte order mark, and
+		// without one take big-endian, as RFC 2781 says. The Encoding
+		// Standard would take little-endian.
+		enc = unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case "utf-16le":
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case "utf-16be":
+		enc = unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	default:
+		var err error
+		if enc, err = htmlindex.Get(charset); err != nil {
+			return nil, fmt.Errorf("%w %q", ErrUnsupportedCharset, charset)
+		}
+	}
+	return enc.NewDecoder().Bytes(body)
+}
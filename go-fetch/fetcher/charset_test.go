@@ -0,0 +1,67 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectCharset(t *testing.T) {
+	for _, c := range []struct{ contentType, body, want string }{
+		{"text/html; charset=ISO-8859-1", "", "iso-8859-1"},
+		{"text/html", `<html><head><meta charset="windows-1252">`, "windows-1252"},
+		{"", `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">`, "shift_jis"},
+		{"text/plain", "\xff\xfeh\x00", "utf-16le"},
+		{"text/plain", "\xfe\xff\x00h", "utf-16be"},
+		{"text/plain", "plain text", ""},
+	} {
+		if got := DetectCharset(c.contentType, []byte(c.body)); got != c.want {
+			t.Errorf("DetectCharset(%q, %q) = %q, want %q", c.contentType, c.body, got, c.want)
+		}
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	for _, c := range []struct{ charset, body, want string }{
+		{"iso-8859-1", "caf\xe9 \x80 \x93q\x94", "café € “q”"},
+		{"utf-8", "\xef\xbb\xbfcaf\xc3\xa9", "café"},
+		{"utf-16be", "\xfe\xff\x00h\xd8\x3d\xde\x00", "h😀"},
+		{"utf-16le", "\xff\xfeh\x00\x3d\xd8\x00\xde", "h😀"},
+		{"utf-16", "\xfe\xff\x00h\x00i", "hi"}, // big-endian, as its byte order mark says
+		{"utf-16", "\xff\xfeh\x00i\x00", "hi"}, // little-endian, as its byte order mark says
+		{"UTF-16", "\x00h\x00i", "hi"},         // no byte order mark: big-endian, as RFC 2781 says
+		{"Shift_JIS", "\x93\xfa\x96\x7b", "日本"},
+		{"gbk", "\xd6\xd0\xce\xc4", "中文"},
+		{"iso-8859-2", "\xafaba \xb3", "Żaba ł"},
+		{"koi8-r", "\xcd\xc9\xd2", "мир"},
+	} {
+		got, err := DecodeCharset([]byte(c.body), c.charset)
+		if err != nil || string(got) != c.want {
+			t.Errorf("DecodeCharset(%q, %q) = %q, %v; want %q", c.body, c.charset, got, err, c.want)
+		}
+	}
+	if _, err := DecodeCharset(nil, "x-no-such-charset"); !errors.Is(err, ErrUnsupportedCharset) {
+		t.Errorf("DecodeCharset of x-no-such-charset: err %v, want ErrUnsupportedCharset", err)
+	}
+}
@@ -0,0 +1,64 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// A CheckError reports that the body fetched from URL failed the check
+// given in BatchOptions.Check, although the status was fine.
+type CheckError struct {
+	URL string
+	Err error // returned by the check
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("fetch %s: %v", RedactURL(e.URL), e.Err)
+}
+
+func (e *CheckError) Unwrap() error { return e.Err }
+
+// BodyContains returns a check for BatchOptions.Check that fails unless
+// the body contains s.
+func BodyContains(s string) func([]byte) error {
+	return func(body []byte) error {
+		if !bytes.Contains(body, []byte(s)) {
+			return fmt.Errorf("body does not contain %q", s)
+		}
+		return nil
+	}
+}
+
+// BodyMatches returns a check for BatchOptions.Check that fails unless re
+// matches the body.
+func BodyMatches(re *regexp.Regexp) func([]byte) error {
+	return func(body []byte) error {
+		if !re.Match(body) {
+			return fmt.Errorf("body does not match %s", re)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestFetchBatchCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("status: healthy"))
+		case "/sick":
+			w.Write([]byte("status: degraded"))
+		default:
+			http.Error(w, "healthy", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/ok", srv.URL + "/sick", srv.URL + "/missing"}
+
+	results := FetchBatch(urls, BatchOptions{Check: BodyContains("healthy")})
+	if r := results[0]; r.Failed() || r.Body != nil {
+		t.Errorf("/ok: err %v, %d-byte body kept; want it to pass and the body dropped", r.Err, len(r.Body))
+	}
+	var ce *CheckError
+	if r := results[1]; !errors.As(r.Err, &ce) || ce.URL != urls[1] || r.StatusCode != http.StatusOK {
+		t.Errorf("/sick: status %d, err %v; want a *CheckError despite the 200", r.StatusCode, r.Err)
+	}
+	if r := results[2]; r.Err != nil || r.StatusCode != http.StatusNotFound {
+		t.Errorf("/missing: status %d, err %v; want the 404 alone, its body not checked", r.StatusCode, r.Err)
+	}
+}
+
+func TestBodyMatches(t *testing.T) {
+	check := BodyMatches(regexp.MustCompile(`"version":\s*"2\.`))
+	if err := check([]byte(`{"version": "2.1"}`)); err != nil {
+		t.Errorf("BodyMatches of a matching body = %v", err)
+	}
+	if err := check([]byte(`{"version": "1.9"}`)); err == nil {
+		t.Error("BodyMatches of a body that does not match succeeded")
+	}
+	if err := BodyContains("x")([]byte("y")); err == nil || err.Error() != `body does not contain "x"` {
+		t.Errorf("BodyContains error = %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultMaxIdleConnsPerHost is the number of idle connections kept open to
+// each host for reuse when ClientOptions does not say otherwise. It is well
+// above net/http's default of 2, so that a batch of concurrent fetches from
+// one host does not keep opening new connections.
+const DefaultMaxIdleConnsPerHost = 16
+
+// The defaults for the rest of the connection pool, used when ClientOptions
+// leaves them zero: 100 idle connections in all, kept for 90 seconds, and no
+// limit on the connections open to one host.
+const (
+	DefaultMaxIdleConns    = 100
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// ClientOptions configures the client returned by NewClient. The zero value
+// gives the settings used by the package-level fetch functions.
+type ClientOptions struct {
+	Timeout             time.Duration // time allowed for each whole request; zero means DefaultTimeout
+	MaxIdleConnsPerHost int           // idle connections kept per host; zero means DefaultMaxIdleConnsPerHost
+	MaxIdleConns        int           // idle connections kept in all; zero means DefaultMaxIdleConns
+	MaxConnsPerHost     int           // connections open to one host, busy or idle; zero means no limit
+	IdleConnTimeout     time.Duration // how long an idle connection is kept; zero means DefaultIdleConnTimeout
+
+	// Proxy is the proxy to send every request through: http, https,
+	// socks5 and socks5h URLs are supported. If nil, the proxy is taken
+	// from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy *url.URL
+
+	// UnixSocket, if set, is the path of a Unix domain socket, such as
+	// /var/run/docker.sock, to which every connection is made whatever the
+	// host in the URL; the URL still supplies the path and query. No proxy
+	// is used.
+	UnixSocket string
+
+	// LocalAddr, if not nil, is the local IP address that connections are
+	// made from, which picks the network interface they leave by on a
+	// machine with several. If nil, the operating system chooses.
+	LocalAddr net.IP
+
+	// Network restricts connections to one address family: "tcp4" for IPv4
+	// only or "tcp6" for IPv6 only. Empty means the family chosen by
+	// SetNetwork, which by default is "tcp", either.
+	Network string
+
+	// DNSServer, if set, is the address of the DNS server, such as 8.8.8.8
+	// or [2001:4860:4860::8888]:53, that host names are looked up with in
+	// place of the system's resolver; the port defaults to 53. DNSNetwork
+	// is "udp", the default, or "tcp" to query the server over TCP.
+	DNSServer  string
+	DNSNetwork string
+
+	// DialAttempts is how many times each connection, lookup included, is
+	// attempted before the request fails, with a short pause between
+	// attempts, so that a flaky resolver or network can be ridden out.
+	// Zero means one attempt.
+	DialAttempts int
+
+	// RootCAs, if not nil, holds the certificate authorities that server
+	// certificates are verified against, in place of the system's, so that
+	// servers signed by an internal CA can be fetched without turning
+	// verification off. LoadCertPool reads one from a PEM file.
+	RootCAs *x509.CertPool
+
+	// Certificates are presented to servers that ask for a client
+	// certificate, as those using mutual TLS do. tls.LoadX509KeyPair
+	// loads one from PEM files.
+	Certificates []tls.Certificate
+
+	// NoRedirect stops the client following redirects: a 3xx response is
+	// returned as it is, with its Location header, for the caller to see.
+	NoRedirect bool
+
+	// MaxHeaderBytes and MaxContentLength, if positive, protect against
+	// hostile servers by refusing responses whose header is larger than
+	// MaxHeaderBytes or whose body is longer than MaxContentLength, with a
+	// *TooLargeError. A declared Content-Length over the limit is refused
+	// before any of the body is read; a body of undeclared length fails
+	// when it passes the limit. MaxContentLength bounds a compressed body
+	// twice: as it comes over the wire, and again once decompressed, so
+	// that a small body cannot unpack into a huge one.
+	MaxHeaderBytes   int64
+	MaxContentLength int64
+
+	// HTTP1 disables HTTP/2, so that every request is made over HTTP/1.1
+	// even to servers that offer HTTP/2. Result.Proto says which protocol
+	// each response came by. net/http cannot send HTTP/1.0 requests.
+	HTTP1 bool
+
+	// Headers are set on every request the client sends that does not set
+	// them itself, such as the Authorization of an authenticated API, so
+	// that every function given the client sends them. A User-Agent given
+	// here replaces DefaultUserAgent.
+	Headers map[string]string
+}
+
+// NewClient returns an HTTP client whose transport keeps connections alive
+// and attempts HTTP/2, so that fetches from the same host share connections.
+// It also fetches file:// URLs, reading them from the local file system.
+// Pass the client to functions such as FetchAllWithClient, and reuse it
+// across calls: each client has its own connection pool.
+func NewClient(opts ClientOptions) *http.Client {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	var rt http.RoundTripper = newTransport(opts)
+	if opts.MaxHeaderBytes > 0 || opts.MaxContentLength > 0 {
+		rt = &sizeLimiter{rt: rt, maxHeader: opts.MaxHeaderBytes, maxBody: opts.MaxContentLength}
+	}
+	if len(opts.Headers) > 0 {
+		rt = &headerTransport{rt: rt, headers: opts.Headers}
+	}
+	client := &http.Client{Timeout: timeout, Transport: rt}
+	if opts.NoRedirect {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// newTransport returns a transport configured as NewClient describes.
+func newTransport(opts ClientOptions) *http.Transport {
+	perHost := opts.MaxIdleConnsPerHost
+	if perHost == 0 {
+		perHost = DefaultMaxIdleConnsPerHost
+	}
+	idle := opts.MaxIdleConns
+	if idle == 0 {
+		idle = DefaultMaxIdleConns
+	}
+	idleTimeout := opts.IdleConnTimeout
+	if idleTimeout == 0 {
+		idleTimeout = DefaultIdleConnTimeout
+	}
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != nil {
+		proxy = http.ProxyURL(opts.Proxy)
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if opts.LocalAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: opts.LocalAddr} // port 0: any free port
+	}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		switch {
+		case opts.Network != "":
+			network = opts.Network
+		case defaultNetwork != "":
+			network = defaultNetwork
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	if opts.DNSServer != "" {
+		dialer.Resolver = newResolver(opts.DNSServer, opts.DNSNetwork)
+	}
+	if opts.UnixSocket != "" {
+		proxy = nil
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", opts.UnixSocket)
+		}
+	}
+	if opts.DialAttempts > 1 {
+		dial = retryDial(dial, opts.DialAttempts)
+	}
+	t := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          idle,
+		MaxIdleConnsPerHost:   perHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       idleTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if opts.MaxHeaderBytes > 0 {
+		t.MaxResponseHeaderBytes = opts.MaxHeaderBytes
+	}
+	if opts.HTTP1 {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{} // non-nil and empty: no HTTP/2 over TLS
+	}
+	if opts.RootCAs != nil || len(opts.Certificates) > 0 {
+		t.TLSClientConfig = &tls.Config{RootCAs: opts.RootCAs, Certificates: opts.Certificates}
+	}
+	t.RegisterProtocol("file", fileTransport{})
+	return t
+}
+
+// defaultNetwork is the network set by SetNetwork; empty means "tcp".
+var defaultNetwork string
+
+// SetNetwork restricts the connections made by the package-level fetch
+// functions, and by clients from NewClient whose options do not choose, to
+// one address family: network is "tcp4" for IPv4 only, "tcp6" for IPv6
+// only, or "tcp", the default, for either. Set it before fetching, not
+// while fetches are in progress.
+func SetNetwork(network string) error {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("unknown network %q: want tcp, tcp4 or tcp6", network)
+	}
+	defaultNetwork = network
+	sharedTransport.CloseIdleConnections() // they may be of the other family
+	return nil
+}
+
+// sharedTransport is used by the package-level fetch functions, unless
+// Transport is set, so that they reuse connections across calls.
+var sharedTransport = newTransport(ClientOptions{})
+
+// Transport, if not nil, sends the requests of the package-level fetch
+// functions in place of the package's own transport. Tests can set it to
+// the Client().Transport of an httptest.Server, or to a RoundTripper that
+// answers without touching the network. Set it before fetching, not while
+// fetches are in progress. Functions given a client use that client's
+// transport instead.
+var Transport http.RoundTripper
+
+// transport returns the transport the package-level fetch functions use.
+func transport() http.RoundTripper {
+	if Transport != nil {
+		return Transport
+	}
+	return sharedTransport
+}
+
+// clientWithTimeout returns a client using transport() that allows each
+// request at most timeout.
+func clientWithTimeout(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: transport(), Timeout: timeout}
+}
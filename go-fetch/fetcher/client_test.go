@@ -0,0 +1,285 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper that answers with a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// countingServer returns an unstarted server that counts in *conns the
+// connections made to it.
+func countingServer(conns *atomic.Int32) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	return srv
+}
+
+func TestNewClientReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := countingServer(&conns)
+	srv.Start()
+	defer srv.Close()
+	client := NewClient(ClientOptions{})
+	urls := []string{srv.URL + "/1", srv.URL + "/2", srv.URL + "/3", srv.URL + "/4"}
+	for i := 0; i < 3; i++ {
+		for _, r := range FetchAllWithClient(client, urls, 2) {
+			if r.Err != nil {
+				t.Fatal(r.Err)
+			}
+		}
+	}
+	if n := conns.Load(); n > 2 {
+		t.Errorf("12 fetches, 2 at a time, opened %d connections; want at most 2", n)
+	}
+}
+
+func TestNewClientHTTP2(t *testing.T) {
+	var conns atomic.Int32
+	srv := countingServer(&conns)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	client := NewClient(ClientOptions{RootCAs: pool})
+	r, err := FetchResponseWithClient(client, srv.URL)
+	if err != nil || r.Proto != "HTTP/2.0" || string(r.Body) != "HTTP/2.0" {
+		t.Errorf("FetchResponseWithClient = %v, %v; want an HTTP/2.0 response", r, err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var sent []string
+	Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.URL.String())
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("mocked")),
+			Request:    req,
+		}, nil
+	})
+	defer func() { Transport = nil }()
+
+	const rawURL = "http://no-such-host.example/page"
+	r, err := FetchResponse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Body) != "mocked" || len(sent) != 1 || sent[0] != rawURL {
+		t.Errorf("FetchResponse = %q after sending %q; want mocked, through Transport", r.Body, sent)
+	}
+}
+
+func TestFetchToWithClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("over TLS"))
+	}))
+	defer srv.Close()
+	var buf bytes.Buffer
+	if n, err := FetchToWithClient(srv.Client(), &buf, srv.URL); err != nil || n != 8 || buf.String() != "over TLS" {
+		t.Errorf("FetchToWithClient = %d, %v, wrote %q; want 8 bytes of over TLS", n, err, buf.String())
+	}
+}
+
+func TestNewClientUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "s")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + r.URL.RequestURI()))
+	}))
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	client := NewClient(ClientOptions{UnixSocket: sock})
+	r, err := FetchResponseWithClient(client, "http://docker/v1/info?all=1")
+	if err != nil || string(r.Body) != "docker/v1/info?all=1" {
+		t.Errorf("FetchResponseWithClient over %s = %v; want the URL's host, path and query sent", sock, err)
+	}
+}
+
+func TestNewClientLocalAddr(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		w.Write([]byte(host))
+	}))
+	defer srv.Close()
+
+	client := NewClient(ClientOptions{LocalAddr: net.ParseIP("127.0.0.1")})
+	if r, err := FetchResponseWithClient(client, srv.URL); err != nil || string(r.Body) != "127.0.0.1" {
+		t.Errorf("FetchResponseWithClient from 127.0.0.1 = %v, %v; want the connection made from 127.0.0.1", r, err)
+	}
+	client = NewClient(ClientOptions{LocalAddr: net.ParseIP("::1")})
+	if _, err := FetchResponseWithClient(client, srv.URL); err == nil {
+		t.Error("connected to an IPv4 server from an IPv6 address")
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if _, err := FetchResponseWithClient(NewClient(ClientOptions{Network: "tcp4"}), srv.URL); err != nil {
+		t.Errorf("tcp4 to %s: %v", srv.URL, err)
+	}
+	if _, err := FetchResponseWithClient(NewClient(ClientOptions{Network: "tcp6"}), srv.URL); err == nil {
+		t.Errorf("tcp6 to %s succeeded, want an error", srv.URL)
+	}
+
+	if err := SetNetwork("udp"); err == nil {
+		t.Error(`SetNetwork("udp") succeeded, want an error`)
+	}
+	defer func() { defaultNetwork = "" }()
+	if err := SetNetwork("tcp6"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FetchResponse(srv.URL); err == nil {
+		t.Errorf("after SetNetwork(tcp6), FetchResponse(%s) succeeded, want an error", srv.URL)
+	}
+	if _, err := FetchResponseWithClient(NewClient(ClientOptions{Network: "tcp4"}), srv.URL); err != nil {
+		t.Errorf("after SetNetwork(tcp6), a tcp4 client: %v; want its own network to win", err)
+	}
+}
+
+// clientCertificate returns a self-signed certificate for a client to
+// present in a TLS handshake.
+func clientCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestNewClientCertificates(t *testing.T) {
+	cert := clientCertificate(t)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+
+	client := NewClient(ClientOptions{RootCAs: rootCAs, Certificates: []tls.Certificate{cert}})
+	if r, err := FetchResponseWithClient(client, srv.URL); err != nil || string(r.Body) != "test client" {
+		t.Errorf("FetchResponseWithClient with a client certificate = %v; want the server to see it", err)
+	}
+	if _, err := FetchResponseWithClient(NewClient(ClientOptions{RootCAs: rootCAs}), srv.URL); err == nil {
+		t.Error("fetched from a server requiring a client certificate without one")
+	}
+}
+
+func TestNewClientHTTP1(t *testing.T) {
+	var conns atomic.Int32
+	srv := countingServer(&conns)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	client := NewClient(ClientOptions{RootCAs: pool, HTTP1: true})
+	r, err := FetchResponseWithClient(client, srv.URL)
+	if err != nil || r.Proto != "HTTP/1.1" || string(r.Body) != "HTTP/1.1" {
+		t.Errorf("FetchResponseWithClient with HTTP1 = %v, %v; want an HTTP/1.1 response from a server offering HTTP/2", r, err)
+	}
+}
+
+func TestNewTransportPool(t *testing.T) {
+	tr := newTransport(ClientOptions{})
+	if tr.MaxIdleConns != DefaultMaxIdleConns || tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost ||
+		tr.IdleConnTimeout != DefaultIdleConnTimeout || tr.MaxConnsPerHost != 0 {
+		t.Errorf("newTransport with no options: %d idle, %d per host, %v idle timeout, %d conns per host; want the defaults",
+			tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.MaxConnsPerHost)
+	}
+	tr = newTransport(ClientOptions{MaxIdleConns: 7, MaxIdleConnsPerHost: 3, IdleConnTimeout: time.Second, MaxConnsPerHost: 2})
+	if tr.MaxIdleConns != 7 || tr.MaxIdleConnsPerHost != 3 || tr.IdleConnTimeout != time.Second || tr.MaxConnsPerHost != 2 {
+		t.Errorf("newTransport: %d idle, %d per host, %v idle timeout, %d conns per host; want 7, 3, 1s and 2",
+			tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.MaxConnsPerHost)
+	}
+
+	var conns atomic.Int32
+	srv := countingServer(&conns)
+	srv.Start()
+	defer srv.Close()
+	client := NewClient(ClientOptions{MaxConnsPerHost: 1})
+	urls := []string{srv.URL + "/1", srv.URL + "/2", srv.URL + "/3", srv.URL + "/4"}
+	for _, r := range FetchAllWithClient(client, urls, len(urls)) {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("4 concurrent fetches with MaxConnsPerHost 1 opened %d connections, want 1", n)
+	}
+}
@@ -0,0 +1,82 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// FetchConcatenated fetches urls as opts describes and writes their bodies
+// to w one after another, each after a line "===== URL =====" naming the
+// URL it came from, so that many small text resources can be collected in
+// one file. The bodies are written whole, one at a time, as the fetches
+// complete, or in input order if opts.Ordered is set; failed fetches write
+// nothing. A body that does not end in a newline is given one, so that each
+// separator starts a line.
+//
+// FetchConcatenated returns one Result per URL, in the same order as urls
+// and without their bodies, and the first error writing to w, after which
+// nothing more is written.
+func FetchConcatenated(w io.Writer, urls []string, opts BatchOptions) ([]Result, error) {
+	return FetchConcatenatedContext(context.Background(), w, urls, opts)
+}
+
+// FetchConcatenatedContext is like FetchConcatenated but cancelling ctx
+// aborts the fetches still in progress, as for FetchBatchContext.
+func FetchConcatenatedContext(ctx context.Context, w io.Writer, urls []string, opts BatchOptions) ([]Result, error) {
+	results := make([]Result, len(urls))
+	var werr error
+	each := opts.OnResult
+	opts.KeepBody = true
+	opts.ConcurrentOnResult = false // the writes must not interleave
+	opts.OnResult = func(i int, r Result) {
+		if !r.Failed() && werr == nil {
+			werr = writeChunk(w, r.URL, r.Body)
+		}
+		r.Body = nil // written; the batch need not hold every body
+		results[i] = r
+		if each != nil {
+			each(i, r)
+		}
+	}
+	stream(ctx, urls, opts)
+	return results, werr
+}
+
+// writeChunk writes body to w after a separator line naming url.
+func writeChunk(w io.Writer, url string, body []byte) error {
+	if _, err := fmt.Fprintf(w, "===== %s =====\n", RedactURL(url)); err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if len(body) > 0 && !bytes.HasSuffix(body, []byte("\n")) {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestFetchConcatenated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			time.Sleep(30 * time.Millisecond) // finishes last
+			w.Write([]byte("alpha\n"))
+		case "/b":
+			w.Write([]byte("beta")) // no final newline
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/a", srv.URL + "/missing", srv.URL + "/b"}
+
+	var buf bytes.Buffer
+	results, err := FetchConcatenated(&buf, urls, BatchOptions{Ordered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "===== " + urls[0] + " =====\nalpha\n===== " + urls[2] + " =====\nbeta\n"
+	if buf.String() != want {
+		t.Errorf("FetchConcatenated wrote %q, want %q", buf.String(), want)
+	}
+	if len(results) != 3 || results[1].StatusCode != http.StatusNotFound || results[0].Body != nil || results[0].Bytes != 6 {
+		t.Errorf("FetchConcatenated results = %+v; want three, without bodies", results)
+	}
+
+	if _, err := FetchConcatenated(failWriter{}, urls, BatchOptions{}); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("FetchConcatenated to a failing writer = %v, want its error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// FetchIfChanged is like FetchResponse but only downloads the content if
+// it has changed since a previous fetch that returned etag and, or,
+// lastModified, which are sent as If-None-Match and If-Modified-Since. An
+// empty etag or a zero lastModified is not sent. If the server answers 304
+// Not Modified, Result.NotModified is set and Result.Body is empty.
+//
+// To poll, keep the ETag and Last-Modified headers of each Result and pass
+// them to the next call; see LastModified.
+func FetchIfChanged(url, etag string, lastModified time.Time) (*Result, error) {
+	req, err := newRequest(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if !lastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", lastModified.UTC().Format(http.TimeFormat))
+	}
+	r, err := fetchRequest(clientWithTimeout(DefaultTimeout), req)
+	if r != nil {
+		r.NotModified = r.StatusCode == http.StatusNotModified
+	}
+	return r, err
+}
+
+// LastModified returns the time in the Last-Modified header of r, or the
+// zero time if there is none or it cannot be parsed.
+func LastModified(r *Result) time.Time {
+	t, err := http.ParseTime(r.Header.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
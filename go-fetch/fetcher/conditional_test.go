@@ -0,0 +1,66 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchIfChanged(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "page.html", modified, strings.NewReader("content"))
+	}))
+	defer srv.Close()
+
+	r, err := FetchIfChanged(srv.URL, "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NotModified || string(r.Body) != "content" || r.Header.Get("ETag") != `"v1"` || !LastModified(r).Equal(modified) {
+		t.Fatalf("first fetch = %d %q, ETag %q, Last-Modified %v; want the content", r.StatusCode, r.Body, r.Header.Get("ETag"), LastModified(r))
+	}
+	for _, c := range []struct {
+		etag string
+		lm   time.Time
+	}{
+		{r.Header.Get("ETag"), time.Time{}},
+		{"", LastModified(r)},
+	} {
+		r, err := FetchIfChanged(srv.URL, c.etag, c.lm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.NotModified || len(r.Body) != 0 {
+			t.Errorf("FetchIfChanged(%q, %v) = %d, %d bytes; want 304 Not Modified", c.etag, c.lm, r.StatusCode, len(r.Body))
+		}
+	}
+	if r, err := FetchIfChanged(srv.URL, `"v0"`, time.Time{}); err != nil || r.NotModified {
+		t.Errorf("stale ETag: FetchIfChanged = %v, %v; want the content again", r, err)
+	}
+}
@@ -0,0 +1,111 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// A Config holds default settings for a run of fetches, as read by
+// LoadConfig from a JSON file such as
+//
+//	{
+//		"headers": {"Accept-Language": "en"},
+//		"user": "alice",
+//		"password": "secret",
+//		"proxy": "socks5://127.0.0.1:1080",
+//		"timeout": "15s",
+//		"concurrency": 8
+//	}
+//
+// Every field is optional; a zero value means no default.
+type Config struct {
+	Headers     map[string]string // sent with every request
+	User        string            // with Password, for HTTP basic auth
+	Password    string
+	Proxy       string        // in the form ParseProxy accepts
+	Timeout     time.Duration // for each whole request
+	Concurrency int           // maximum fetches in flight
+}
+
+// configFile is the JSON form of a Config.
+type configFile struct {
+	Headers     map[string]string `json:"headers"`
+	User        string            `json:"user"`
+	Password    string            `json:"password"`
+	Proxy       string            `json:"proxy"`
+	Timeout     string            `json:"timeout"` // as time.ParseDuration reads it
+	Concurrency int               `json:"concurrency"`
+}
+
+// LoadConfig reads the Config in the named JSON file. Unknown fields are
+// an error, so that a misspelt setting is not silently ignored, as are a
+// proxy that ParseProxy rejects and a timeout that is not a duration.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var f configFile
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("config %s: %v", path, err)
+	}
+	c := &Config{
+		Headers:     f.Headers,
+		User:        f.User,
+		Password:    f.Password,
+		Proxy:       f.Proxy,
+		Concurrency: f.Concurrency,
+	}
+	if f.Proxy != "" {
+		if _, err := ParseProxy(f.Proxy); err != nil {
+			return nil, fmt.Errorf("config %s: %v", path, err)
+		}
+	}
+	if f.Timeout != "" {
+		if c.Timeout, err = time.ParseDuration(f.Timeout); err != nil || c.Timeout <= 0 {
+			return nil, fmt.Errorf("config %s: invalid timeout %q", path, f.Timeout)
+		}
+	}
+	return c, nil
+}
+
+// RequestHeaders returns the headers to send with every request: c.Headers
+// and, if c.User is set, an Authorization header for basic auth.
+func (c *Config) RequestHeaders() map[string]string {
+	h := make(map[string]string, len(c.Headers)+1)
+	for k, v := range c.Headers {
+		h[k] = v
+	}
+	if c.User != "" {
+		h["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.User+":"+c.Password))
+	}
+	return h
+}
@@ -0,0 +1,84 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(data string) string {
+		name := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	c, err := LoadConfig(write(`{
+		"headers": {"Accept-Language": "en"},
+		"user": "alice",
+		"password": "secret",
+		"proxy": "socks5://127.0.0.1:1080",
+		"timeout": "15s",
+		"concurrency": 8
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Headers:     map[string]string{"Accept-Language": "en"},
+		User:        "alice",
+		Password:    "secret",
+		Proxy:       "socks5://127.0.0.1:1080",
+		Timeout:     15 * time.Second,
+		Concurrency: 8,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("LoadConfig = %+v, want %+v", c, want)
+	}
+	h := c.RequestHeaders()
+	if h["Accept-Language"] != "en" || h["Authorization"] != "Basic YWxpY2U6c2VjcmV0" {
+		t.Errorf("RequestHeaders = %v, want Accept-Language and basic auth for alice", h)
+	}
+
+	for _, data := range []string{
+		`{"timout": "15s"}`,
+		`{"timeout": "soon"}`,
+		`{"timeout": "-1s"}`,
+		`{"proxy": "ftp://proxy"}`,
+		`{"concurrency": "eight"}`,
+	} {
+		if c, err := LoadConfig(write(data)); err == nil {
+			t.Errorf("LoadConfig(%s) = %+v, want an error", data, c)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("LoadConfig of a missing file = %v, want a not-exist error", err)
+	}
+}
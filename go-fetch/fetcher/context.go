@@ -0,0 +1,59 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+)
+
+// FetchContext is like Fetch but aborts the request, including the body
+// copy, when ctx is cancelled. It returns the number of bytes printed.
+func FetchContext(ctx context.Context, url string) (int64, error) {
+	return fetchTo(ctx, os.Stdout, url, DefaultTimeout)
+}
+
+// FetchWithBufferContext is like FetchWithBuffer but aborts the request when
+// ctx is cancelled. The whole body is read into memory before it is printed,
+// so nothing is printed for a cancelled request.
+func FetchWithBufferContext(ctx context.Context, url string) (int64, error) {
+	var buf bytes.Buffer
+	if _, err := fetchTo(ctx, &buf, url, DefaultTimeout); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(os.Stdout)
+}
+
+// FetchConcurrentContext is like FetchConcurrent but aborts the request when
+// ctx is cancelled, so a caller running many of them can stop the rest once
+// one has failed. It sends exactly one line on ch.
+func FetchConcurrentContext(ctx context.Context, url string, ch chan<- string) {
+	r := fetchResult(ctx, url, &BatchOptions{Client: clientWithTimeout(DefaultTimeout)})
+	if r.Err != nil {
+		ch <- fmt.Sprint(r.Err) // send to channel ch
+		return
+	}
+	ch <- fmt.Sprintf("%.2fs  %7d  %s", r.Elapsed.Seconds(), r.Bytes, url)
+}
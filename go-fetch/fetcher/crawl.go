@@ -0,0 +1,133 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// crawlConcurrency is the most fetches Crawl has in flight at once.
+const crawlConcurrency = 8
+
+// Crawl fetches seed and then, breadth first, the pages it links to, the
+// pages they link to, and so on, to at most maxDepth links away from seed.
+// Each URL is fetched once, however many pages link to it. If sameHostOnly
+// is set, only links to seed's host are followed. Links are taken only from
+// successful HTML responses; see ExtractLinks. Pages that robots.txt
+// disallows are skipped, as CrawlWith describes.
+//
+// Crawl returns a Result for every URL fetched, level by level, with the
+// seed first. The bodies are not kept.
+func Crawl(seed string, maxDepth int, sameHostOnly bool) []Result {
+	return CrawlWith(seed, CrawlOptions{MaxDepth: maxDepth, SameHostOnly: sameHostOnly})
+}
+
+// CrawlOptions configures CrawlWith.
+type CrawlOptions struct {
+	MaxDepth     int          // follow links at most this many away from the seed
+	SameHostOnly bool         // follow only links to the seed's host
+	Client       *http.Client // sends every request, robots.txt included; nil as for BatchOptions
+
+	// IgnoreRobots fetches pages even when robots.txt disallows them. By
+	// default the robots.txt file of each site is fetched once, when the
+	// crawl first reaches the site, and the pages it disallows for this
+	// package's user agent, or for *, are skipped.
+	IgnoreRobots bool
+
+	// Blocked, if non-nil, is called with each URL skipped because
+	// robots.txt disallows it, the seed included.
+	Blocked func(rawURL string)
+}
+
+// CrawlWith is like Crawl but crawls as opts describes.
+func CrawlWith(seed string, opts CrawlOptions) []Result {
+	var host string
+	if u, err := url.Parse(seed); err == nil {
+		host = u.Host
+	}
+	robots := robotsCache{client: opts.Client}
+	permitted := func(link string) bool {
+		if opts.IgnoreRobots || robots.allowed(link) {
+			return true
+		}
+		if opts.Blocked != nil {
+			opts.Blocked(link)
+		}
+		return false
+	}
+	if !permitted(seed) {
+		return nil
+	}
+	visited := map[string]bool{seed: true}
+	batch := BatchOptions{Client: opts.Client, Concurrency: crawlConcurrency, KeepBody: true}
+	var all []Result
+	level := []string{seed}
+	for depth := 0; len(level) > 0; depth++ {
+		results := FetchBatch(level, batch)
+		level = nil
+		for i := range results {
+			r := &results[i]
+			if depth < opts.MaxDepth && !r.Failed() && isHTML(r) {
+				base, err := url.Parse(r.FinalURL)
+				if err != nil {
+					continue
+				}
+				for _, link := range ExtractLinks(r.Body, base) {
+					if visited[link] {
+						continue
+					}
+					if opts.SameHostOnly && !sameHost(link, host) {
+						continue
+					}
+					visited[link] = true
+					if permitted(link) {
+						level = append(level, link)
+					}
+				}
+			}
+			r.Body = nil // the whole site need not stay in memory
+		}
+		all = append(all, results...)
+	}
+	return all
+}
+
+// isHTML reports whether r's body is an HTML document: its Content-Type
+// says so, or it has none.
+func isHTML(r *Result) bool {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && (mt == "text/html" || mt == "application/xhtml+xml")
+}
+
+// sameHost reports whether link is on host.
+func sameHost(link, host string) bool {
+	u, err := url.Parse(link)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,139 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// siteServer serves the HTML pages in site, keyed by path, linking to
+// other by the placeholder OTHER; other paths are not found.
+func siteServer(site map[string]string, other string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := site[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(strings.ReplaceAll(page, "OTHER", other)))
+	}))
+}
+
+func TestCrawl(t *testing.T) {
+	other := siteServer(map[string]string{"/": "elsewhere"}, "")
+	defer other.Close()
+	srv := siteServer(map[string]string{
+		"/":  `<a href="/a">a</a> <a href="/b">b</a> <a href="OTHER/">other</a>`,
+		"/a": `<a href="/">home</a> <a href="/b">b</a> <a href="/c">c</a>`,
+		"/b": `no links`,
+		"/c": `<a href="/d">d</a>`,
+	}, other.URL)
+	defer srv.Close()
+
+	tests := []struct {
+		depth    int
+		sameHost bool
+		want     []string
+	}{
+		{0, true, []string{"/"}},
+		{1, true, []string{"/", "/a", "/b"}},
+		{2, true, []string{"/", "/a", "/b", "/c"}},
+		{3, true, []string{"/", "/a", "/b", "/c", "/d"}},
+		{1, false, []string{"/", "/a", "/b", "OTHER/"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range Crawl(srv.URL+"/", tt.depth, tt.sameHost) {
+			got = append(got, strings.Replace(strings.TrimPrefix(r.URL, srv.URL), other.URL, "OTHER", 1))
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Crawl(depth %d, sameHost %v) fetched %q, want %q", tt.depth, tt.sameHost, got, tt.want)
+		}
+	}
+}
+
+// sessionServer serves a site whose pages other than /login answer 403
+// unless the request carries the session cookie /login sets.
+func sessionServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret", Path: "/"})
+			return
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s3cret" {
+			http.Error(w, "log in first", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<a href="/private">private</a>`))
+		}
+	}))
+}
+
+func loggedIn(t *testing.T, srv *httptest.Server) *http.Client {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	if _, err := FetchBatchError([]string{srv.URL + "/login"}, BatchOptions{Client: client}); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCrawlWithClient(t *testing.T) {
+	srv := sessionServer()
+	defer srv.Close()
+	results := CrawlWith(srv.URL+"/", CrawlOptions{MaxDepth: 1, SameHostOnly: true, Client: loggedIn(t, srv)})
+	if len(results) != 2 {
+		t.Fatalf("crawled %d pages, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.Failed() {
+			t.Errorf("%s: status %d, err %v; the session cookie was not sent", r.URL, r.StatusCode, r.Err)
+		}
+	}
+}
+
+func TestMirrorWithClient(t *testing.T) {
+	srv := sessionServer()
+	defer srv.Close()
+	root := t.TempDir()
+	if _, _, err := FetchToMirrorWith(srv.URL+"/", root, MirrorOptions{}); err == nil {
+		t.Error("mirrored a page without logging in")
+	}
+	name, _, err := FetchToMirrorWith(srv.URL+"/", root, MirrorOptions{Client: loggedIn(t, srv), Accept: []string{"text/html"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(name); err != nil || len(b) == 0 {
+		t.Errorf("mirrored %s: %q, %v", name, b, err)
+	}
+}
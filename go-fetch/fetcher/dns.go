@@ -0,0 +1,67 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrDNS matches, with errors.Is, every *DNSError, so that callers can
+// tell a host that does not resolve from one that refuses the connection
+// or does not answer in time.
+var ErrDNS = errors.New("DNS lookup failed")
+
+// A DNSError reports that the host named in URL could not be resolved.
+type DNSError struct {
+	URL  string
+	Host string
+	Err  *net.DNSError // the resolver's error
+}
+
+func (e *DNSError) Error() string {
+	return fmt.Sprintf("fetch %s: DNS lookup failed for %s: %s", RedactURL(e.URL), e.Host, e.Err.Err)
+}
+
+func (e *DNSError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrDNS.
+func (e *DNSError) Is(target error) bool { return target == ErrDNS }
+
+// requestError classifies err, the failure of the request for url that was
+// allowed at most timeout: a failed lookup becomes a *DNSError and a
+// timeout a *TimeoutError, and a *TooLargeError is unwrapped. Other errors
+// are returned unchanged.
+func requestError(url string, timeout time.Duration, err error) error {
+	var de *net.DNSError
+	if errors.As(err, &de) {
+		return &DNSError{URL: url, Host: de.Name, Err: de}
+	}
+	var tle *TooLargeError
+	if errors.As(err, &tle) {
+		return tle // it names the URL already; the *url.Error around it would repeat it
+	}
+	return timeoutError(url, timeout, err)
+}
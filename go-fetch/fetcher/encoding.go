@@ -0,0 +1,133 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent with every request. Because it is set explicitly,
+// the transport leaves decompression to decode, which lets the number of
+// bytes received on the wire be measured.
+const acceptEncoding = "gzip, deflate"
+
+// A countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// A decodedBody is a response body that is decompressed as it is read. It
+// keeps count of the compressed bytes that came over the wire.
+type decodedBody struct {
+	io.Reader                 // the decompressed content
+	wire      *countingReader // the content as sent by the server
+	body      io.Closer       // the original response body
+}
+
+func (b *decodedBody) Close() error { return b.body.Close() }
+
+// decode arranges for the body of resp to be decompressed if the server
+// used gzip or deflate content coding. As net/http does for the encodings
+// it handles itself, the Content-Encoding and Content-Length headers are
+// removed, since they describe the body before decompression. If maxBody
+// is positive, reading more than that many decompressed bytes fails with a
+// *TooLargeError.
+func decode(resp *http.Response, maxBody int64) {
+	wire := &countingReader{r: resp.Body}
+	b := &decodedBody{Reader: wire, wire: wire, body: resp.Body}
+	resp.Body = b
+	enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch enc {
+	case "gzip", "x-gzip", "deflate":
+	default:
+		return
+	}
+	b.Reader = &lazyDecoder{enc: enc, src: wire}
+	if maxBody > 0 { // a small compressed body can decompress to a huge one
+		err := &TooLargeError{URL: resp.Request.URL.String(), Part: "body", Limit: maxBody}
+		b.Reader = &limitedBody{body: io.NopCloser(b.Reader), left: maxBody, err: err}
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// wireBytes returns the number of body bytes of resp received so far on the
+// wire, which is less than the number read if the body is compressed.
+func wireBytes(resp *http.Response) int64 {
+	if b, ok := resp.Body.(*decodedBody); ok {
+		return b.wire.n
+	}
+	return 0
+}
+
+// A lazyDecoder creates its decompressor on the first Read, so that an empty
+// body, as in a 204 or 304 response, is not reported as malformed.
+type lazyDecoder struct {
+	enc string
+	src io.Reader
+	r   io.Reader
+}
+
+func (d *lazyDecoder) Read(p []byte) (int, error) {
+	if d.r == nil {
+		r, err := newDecompressor(d.enc, d.src)
+		if err != nil {
+			return 0, err
+		}
+		d.r = r
+	}
+	return d.r.Read(p)
+}
+
+// newDecompressor returns a reader that decompresses src, which uses the
+// content coding enc. Although HTTP's deflate means zlib-wrapped data, some
+// servers send a raw deflate stream, so both are accepted.
+func newDecompressor(enc string, src io.Reader) (io.Reader, error) {
+	if enc != "deflate" {
+		return gzip.NewReader(src)
+	}
+	br := bufio.NewReader(src)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint(header[0])<<8|uint(header[1]))%31 == 0 { // zlib header: CM=8 and FCHECK valid
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,86 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	content := strings.Repeat("compressible ", 1000)
+	encoded := map[string][]byte{}
+	for _, enc := range []string{"gzip", "deflate", "raw-deflate"} {
+		var buf bytes.Buffer
+		var zw io.WriteCloser
+		switch enc {
+		case "gzip":
+			zw = gzip.NewWriter(&buf)
+		case "deflate":
+			zw = zlib.NewWriter(&buf)
+		default:
+			zw, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		}
+		zw.Write([]byte(content))
+		zw.Close()
+		encoded[enc] = buf.Bytes()
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := strings.TrimPrefix(r.URL.Path, "/")
+		if r.Header.Get("Accept-Encoding") == "" {
+			t.Errorf("%s: no Accept-Encoding sent", enc)
+		}
+		if enc == "empty" {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Encoding", strings.TrimPrefix(enc, "raw-"))
+		w.Write(encoded[enc])
+	}))
+	defer srv.Close()
+
+	for enc, body := range encoded {
+		r, err := FetchResponse(srv.URL + "/" + enc)
+		if err != nil {
+			t.Errorf("%s: %v", enc, err)
+			continue
+		}
+		if string(r.Body) != content {
+			t.Errorf("%s: FetchResponse = %d bytes, want the %d decompressed", enc, len(r.Body), len(content))
+		}
+		if r.Bytes != int64(len(content)) || r.WireBytes != int64(len(body)) || r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s: Bytes %d, WireBytes %d, Content-Encoding %q; want %d, %d and none", enc, r.Bytes, r.WireBytes, r.Header.Get("Content-Encoding"), len(content), len(body))
+		}
+	}
+	if r, err := FetchResponse(srv.URL + "/empty"); err != nil || r.StatusCode != http.StatusNoContent {
+		t.Errorf("empty gzip body: FetchResponse = %v, %v; want 204 and no error", r, err)
+	}
+}
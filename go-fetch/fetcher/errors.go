@@ -0,0 +1,48 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/url"
+)
+
+// namesURL reports whether the message of err already includes the URL
+// being fetched, as those of the package's error types and of the errors
+// returned by http.Client do.
+func namesURL(err error) bool {
+	var (
+		ue  *url.Error
+		se  *StatusError
+		te  *TimeoutError
+		de  *DNSError
+		re  *RetryError
+		cte *ContentTypeError
+		pe  *PartialReadError
+		ce  *CheckError
+		tle *TooLargeError
+	)
+	return errors.As(err, &ue) || errors.As(err, &se) || errors.As(err, &te) ||
+		errors.As(err, &de) || errors.As(err, &re) || errors.As(err, &cte) ||
+		errors.As(err, &pe) || errors.As(err, &ce) || errors.As(err, &tle)
+}
@@ -0,0 +1,125 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ExtractLinks returns the URLs named by the href and src attributes of the
+// HTML document body, in the order they first appear, with relative URLs
+// resolved against base, or against the document's <base href> if it has
+// one. Only http and https URLs are returned, without their fragments and
+// with each listed once, so that the result can be fed back to the fetcher.
+// If base is nil, relative URLs are dropped.
+//
+// ExtractLinks reads the markup with the tokenizer of golang.org/x/net/html
+// rather than building a parse tree; it does not need a well-formed
+// document, it skips comments and the contents of <script> and <style>
+// elements, and it reads attribute values quoted or not, decoding their
+// character references.
+func ExtractLinks(body []byte, base *url.URL) []string {
+	var links []string
+	seen := make(map[string]bool)
+	sawBase := false // only the first <base href> counts
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		name, attrs, ok := nextTag(z)
+		if !ok {
+			break
+		}
+		if name == "base" { // not a link, whether or not it counts
+			if ref, ok := attrs["href"]; ok && !sawBase {
+				sawBase = true
+				if u, err := resolve(base, ref); err == nil {
+					base = u
+				}
+			}
+			continue
+		}
+		for _, key := range [...]string{"href", "src"} {
+			ref, ok := attrs[key]
+			if !ok {
+				continue
+			}
+			u, err := resolve(base, ref)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				continue // unparsable, relative with no base, or mailto:, javascript: and the like
+			}
+			u.Fragment, u.RawFragment = "", ""
+			if s := u.String(); !seen[s] {
+				seen[s] = true
+				links = append(links, s)
+			}
+		}
+	}
+	return links
+}
+
+// nextTag advances z to the next start tag and returns its lower-case name
+// and its attributes keyed by lower-case name, with their values decoded.
+// As in HTML, the first of repeated attributes wins. It reports false at
+// the end of the document.
+func nextTag(z *html.Tokenizer) (name string, attrs map[string]string, ok bool) {
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return "", nil, false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			n, more := z.TagName()
+			attrs = make(map[string]string)
+			for more {
+				var key, val []byte
+				key, val, more = z.TagAttr()
+				if _, dup := attrs[string(key)]; !dup {
+					attrs[string(key)] = string(val)
+				}
+			}
+			return string(n), attrs, true
+		}
+	}
+}
+
+// resolve parses the attribute value ref and resolves it against base. If
+// base is nil, ref must be absolute.
+func resolve(base *url.URL, ref string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		return base.ResolveReference(u), nil
+	}
+	if !u.IsAbs() {
+		return nil, errRelative
+	}
+	return u, nil
+}
+
+// errRelative is returned by resolve for a relative URL with no base.
+var errRelative = errors.New("relative URL with no base")
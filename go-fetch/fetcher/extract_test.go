@@ -0,0 +1,71 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractLinksLatin1(t *testing.T) {
+	// \xe9 and \xc0 are é and À in Latin-1 but invalid UTF-8, which
+	// strings.ToLower would replace with three-byte runes.
+	body := []byte("<p>caf\xe9 \xc0 \xe9\xe9\xe9\xe9</p><SCRIPT>var s = '<a href=/no>" +
+		strings.Repeat("\xe9", 40) + "';</Script>" +
+		"<p>\xe9\xe9\xe9</p><a href=\"/yes\">caf\xe9</a><style>\xe9 a[href=\"/no2\"]</STYLE><img src=/img.png>")
+	if got := ExtractLinks(body[:len(body)-30], nil); got != nil { // an unclosed <style> must not panic either
+		t.Errorf("ExtractLinks with no base = %q, want none", got)
+	}
+	base, _ := url.Parse("http://example.com/")
+	got := ExtractLinks(body, base)
+	want := []string{"http://example.com/yes", "http://example.com/img.png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractLinks = %q, want %q", got, want)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	body := []byte(`<html><head><base href="/docs/"><base href="/ignored/"></head><body>
+<!-- <a href="/commented"> -->
+<script>document.write('<a href="/scripted">')</script>
+<a href=page.html>unquoted</a>
+<a href="search?q=1&amp;lang=en#top">entity</a>
+<A HREF='page.html#again'>repeated</A>
+<img src="/img.png" src="/second.png">
+<a href="mailto:me@example.com">mail</a>
+<a href="https://other.example/x">absolute</a>
+</body></html>`)
+	base, _ := url.Parse("http://example.com/index.html")
+	got := ExtractLinks(body, base)
+	want := []string{
+		"http://example.com/docs/page.html",
+		"http://example.com/docs/search?q=1&lang=en",
+		"http://example.com/img.png",
+		"https://other.example/x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractLinks = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent header sent with every request unless
+// the caller sets its own.
+const DefaultUserAgent = "go-workspace-fetcher/1.0"
+
+// FetchTo copies the content found at url to w and returns the number of
+// bytes copied. Unlike Fetch it prints nothing itself; any error is returned
+// to the caller. The request is bounded by DefaultTimeout.
+func FetchTo(w io.Writer, url string) (int64, error) {
+	return fetchTo(context.Background(), w, url, DefaultTimeout)
+}
+
+// FetchToWithClient is like FetchTo but sends the request with client, such
+// as one returned by NewClient or by an httptest.Server. The client's own
+// timeout applies.
+func FetchToWithClient(client *http.Client, w io.Writer, url string) (int64, error) {
+	ctx := context.Background()
+	resp, err := getWith(ctx, client, url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return copyBody(ctx, w, resp.Body, url, client.Timeout)
+}
+
+// FetchStream copies the content found at url to w as it arrives and
+// returns the number of bytes copied. The body is never held in memory, so
+// memory use is the same however large it is. Unlike FetchTo, it returns a
+// *StatusError for a 4xx or 5xx response, whose body it does not copy.
+func FetchStream(w io.Writer, url string) (int64, error) {
+	return fetchOK(context.Background(), w, url, DefaultTimeout)
+}
+
+// FetchStreamWithClient is like FetchStream but sends the request with
+// client, whose own timeout and cookie jar apply.
+func FetchStreamWithClient(client *http.Client, w io.Writer, url string) (int64, error) {
+	return fetchOKWith(context.Background(), client, w, url)
+}
+
+// fetchTo copies the content found at url to w, allowing the whole request
+// at most timeout. Timeouts are reported as *TimeoutError. If ctx is done
+// before the copy finishes, fetchTo returns ctx.Err().
+func fetchTo(ctx context.Context, w io.Writer, url string, timeout time.Duration) (int64, error) {
+	resp, err := get(ctx, url, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return copyBody(ctx, w, resp.Body, url, timeout)
+}
+
+// get issues a GET request for url with a client using the package's
+// transport whose timeout, which also bounds reading the body, is timeout.
+// A gzip or deflate compressed body is decompressed as it is read. The
+// caller must close the body.
+func get(ctx context.Context, url string, timeout time.Duration) (*http.Response, error) {
+	return getWith(ctx, clientWithTimeout(timeout), url)
+}
+
+// getWith is like get but sends the request with client.
+func getWith(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return send(client, req)
+}
+
+// newRequest returns a request carrying the headers this package sends by
+// default: User-Agent and Accept-Encoding.
+func newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	return req, nil
+}
+
+// send sends req with client, reporting failed lookups as *DNSError and
+// timeouts as *TimeoutError, and returning the error of req's context if it
+// is done. A gzip or deflate compressed body is decompressed as it is read;
+// a HEAD response has no body, so its headers are left as the server sent
+// them.
+func send(client *http.Client, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	logRequest(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx := req.Context(); ctx.Err() != nil {
+			err = ctx.Err()
+		} else {
+			err = requestError(req.URL.String(), client.Timeout, err)
+		}
+		logf(LevelInfo, "%s %s: %v", req.Method, RedactURL(req.URL.String()), err)
+		return nil, err
+	}
+	logResponse(resp, start)
+	if req.Method != http.MethodHead {
+		checkLength(resp) // before decode, which drops the Content-Length of a compressed body
+		decode(resp, bodyLimit(client.Transport))
+	}
+	return resp, nil
+}
+
+// copyBody copies the body of the response to url into w, reporting errors
+// the same way as get, and as *PartialReadError if the body could not be
+// read to the end.
+func copyBody(ctx context.Context, w io.Writer, body io.Reader, url string, timeout time.Duration) (int64, error) {
+	n, err := io.Copy(w, body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		var tle *TooLargeError
+		if errors.As(err, &tle) {
+			return n, tle
+		}
+		return n, timeoutError(url, timeout, &PartialReadError{URL: url, Bytes: n, Err: err})
+	}
+	return n, nil
+}
+
+// A PartialReadError reports that reading the body of the response to URL
+// failed after Bytes bytes had arrived, as when the connection drops part
+// way through, so that a nearly complete body can be told from one that
+// never started.
+type PartialReadError struct {
+	URL   string
+	Bytes int64
+	Err   error
+}
+
+func (e *PartialReadError) Error() string {
+	return fmt.Sprintf("reading %s: read %d bytes then: %v", RedactURL(e.URL), e.Bytes, e.Err)
+}
+
+func (e *PartialReadError) Unwrap() error { return e.Err }
+
+// A StatusError reports that the server answered a request for URL with an
+// unsuccessful (4xx or 5xx) status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration // wait asked for by a Retry-After header on a 429 or 503; zero if none
+}
+
+// statusError returns a *StatusError describing resp, an unsuccessful
+// response to a request for url.
+func statusError(url string, resp *http.Response) *StatusError {
+	e := &StatusError{URL: url, StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		e.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return e
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch %s: %d %s", RedactURL(e.URL), e.StatusCode, http.StatusText(e.StatusCode))
+}
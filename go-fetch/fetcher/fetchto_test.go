@@ -0,0 +1,109 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchTo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello, world"))
+	}))
+	var buf bytes.Buffer
+	n, err := FetchTo(&buf, srv.URL)
+	if err != nil || n != 12 || buf.String() != "hello, world" {
+		t.Errorf("FetchTo = %d, %v, wrote %q; want 12 bytes of hello, world", n, err, buf.String())
+	}
+	srv.Close()
+	if _, err := FetchTo(&buf, srv.URL); err == nil {
+		t.Errorf("FetchTo of a closed server succeeded, want an error")
+	}
+}
+
+// notifyWriter collects what is written to it in buf, closing got on the
+// first write. It has no ReadFrom, so io.Copy must call Write.
+type notifyWriter struct {
+	buf  bytes.Buffer
+	got  chan struct{}
+	once bool
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	if !w.once {
+		w.once = true
+		close(w.got)
+	}
+	return w.buf.Write(p)
+}
+
+func TestFetchStream(t *testing.T) {
+	w := &notifyWriter{got: make(chan struct{})}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(rw, "not here", http.StatusNotFound)
+			return
+		}
+		io.WriteString(rw, "first ")
+		rw.(http.Flusher).Flush()
+		select {
+		case <-w.got: // the first chunk reached the writer before the body ended
+			io.WriteString(rw, "second")
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	if n, err := FetchStream(w, srv.URL); err != nil || n != 12 || w.buf.String() != "first second" {
+		t.Errorf("FetchStream = %d, %v, wrote %q; want 12 bytes of first second", n, err, w.buf.String())
+	}
+	var buf bytes.Buffer
+	var se *StatusError
+	if n, err := FetchStream(&buf, srv.URL+"/missing"); !errors.As(err, &se) || se.StatusCode != http.StatusNotFound || n != 0 || buf.Len() != 0 {
+		t.Errorf("FetchStream of a 404 = %d, %v, wrote %q; want a *StatusError and nothing written", n, err, buf.String())
+	}
+}
+
+func TestFetchToPartialRead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("ten bytes!")) // then the handler returns and the connection is cut short
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	n, err := FetchTo(&buf, srv.URL)
+	var pe *PartialReadError
+	if !errors.As(err, &pe) || pe.Bytes != 10 || pe.URL != srv.URL || n != 10 {
+		t.Fatalf("FetchTo of a truncated body = %d, %v; want a *PartialReadError after 10 bytes", n, err)
+	}
+	if !strings.Contains(err.Error(), "read 10 bytes then") {
+		t.Errorf("error %q does not say how much was read", err)
+	}
+}
@@ -0,0 +1,446 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFileName is the longest file name FetchToFile will create; most file
+// systems refuse names longer than 255 bytes.
+const maxFileName = 200
+
+// FetchToFile saves the content found at rawURL in a file in destDir,
+// creating destDir if needed, and returns the path of the file. The file
+// is named by DefaultFileName. If a file of that name already exists, a
+// counter is added before the extension: page.html, page-1.html,
+// page-2.html, and so on.
+func FetchToFile(rawURL, destDir string) (string, error) {
+	name, _, err := FetchToFileWith(rawURL, destDir, FileOptions{})
+	return name, err
+}
+
+// FileOptions configures FetchToFileWith. The zero value saves the content
+// as it is, as FetchToFile does.
+type FileOptions struct {
+	// Name, if not nil, names the file for the content found at rawURL
+	// from the URL and the response, in place of DefaultFileName. The name
+	// must be a plain file name: FetchToFileWith fails without saving
+	// anything if it holds a path separator or a NUL byte, or starts with
+	// a dot.
+	Name NameFunc
+
+	// Gzip compresses the content with gzip as it is saved, and adds .gz
+	// to the file name, which saves much space for text.
+	Gzip bool
+}
+
+// FetchToFileWith is FetchToFile configured by opts. It also returns the
+// size of the content, before any compression.
+func FetchToFileWith(rawURL, destDir string, opts FileOptions) (name string, n int64, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	ctx := context.Background()
+	resp, err := get(ctx, rawURL, DefaultTimeout)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", 0, statusError(rawURL, resp)
+	}
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return "", 0, err
+	}
+	if opts.Name != nil {
+		name = opts.Name(rawURL, resp)
+		if !plainFileName(name) {
+			return "", 0, fmt.Errorf("fetch %s: unusable file name %q", RedactURL(rawURL), name)
+		}
+	} else {
+		name = defaultFileName(u, resp)
+	}
+	if opts.Gzip {
+		name += ".gz"
+	}
+	f, err := createUnique(destDir, name)
+	if err != nil {
+		return "", 0, err
+	}
+	n, err = saveBody(ctx, f, resp.Body, rawURL, DefaultTimeout, opts.Gzip)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", 0, err
+	}
+	return f.Name(), n, nil
+}
+
+// A NameFunc returns the name of the file to save the response to a request
+// for rawURL in.
+type NameFunc func(rawURL string, resp *http.Response) string
+
+// DefaultFileName names the file for the response to a request for rawURL
+// with the filename its Content-Disposition header gives, if any, or else
+// by the URL path and query as fileName does. Either way the name is made
+// safe as fileName makes it. resp may be nil, as when only the URL is
+// known.
+func DefaultFileName(rawURL string, resp *http.Response) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		u = &url.URL{}
+	}
+	return defaultFileName(u, resp)
+}
+
+// defaultFileName is DefaultFileName for the parsed URL u.
+func defaultFileName(u *url.URL, resp *http.Response) string {
+	if resp != nil {
+		if name := dispositionName(resp.Header.Get("Content-Disposition")); name != "" {
+			return name
+		}
+	}
+	return fileName(u)
+}
+
+// dispositionName returns the filename given by the Content-Disposition
+// header value cd, without any directory and made safe as fileName does,
+// or "" if there is none or it is not a plain file name once its directory
+// is removed.
+func dispositionName(cd string) string {
+	_, params, err := mime.ParseMediaType(cd) // which decodes filename*=UTF-8''...
+	if err != nil {
+		return ""
+	}
+	name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(params["filename"], `\`, "/")))
+	if !plainFileName(name) {
+		return ""
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if len(base) > maxFileName {
+		base = base[:maxFileName]
+	}
+	return sanitize(base) + sanitize(ext)
+}
+
+// plainFileName reports whether name can be used as it is as the name of a
+// file in a directory: it is not empty, holds no path separator or NUL
+// byte, and does not start with a dot, so that it can neither escape the
+// directory nor hide in it.
+func plainFileName(name string) bool {
+	return name != "" && name[0] != '.' && !strings.ContainsAny(name, "/\\\x00")
+}
+
+// saveBody copies body, the content found at url with a client whose
+// timeout is timeout, to w, compressing it with gzip if gz is set, and
+// returns the number of bytes copied before compression.
+func saveBody(ctx context.Context, w io.Writer, body io.Reader, url string, timeout time.Duration, gz bool) (int64, error) {
+	if !gz {
+		return copyBody(ctx, w, body, url, timeout)
+	}
+	zw := gzip.NewWriter(w)
+	n, err := copyBody(ctx, zw, body, url, timeout)
+	if cerr := zw.Close(); err == nil {
+		err = cerr // flushes the last block
+	}
+	return n, err
+}
+
+// FetchToMirror saves the content found at rawURL under root at a path that
+// mirrors the URL, so that fetching a site builds a browsable local copy:
+// http://example.com/a/b/c.html is saved as root/example.com/a/b/c.html.
+// Directories are created as needed, and a URL whose path is empty or ends
+// in a slash is saved as index.html in the directory it names. Each part of
+// the path is made safe as fileName does. An existing file is replaced, but
+// only once the new content has been fetched in full.
+func FetchToMirror(rawURL, root string) (string, error) {
+	name, _, err := FetchToMirrorWith(rawURL, root, MirrorOptions{})
+	return name, err
+}
+
+// MirrorOptions configures FetchToMirrorWith. The zero value saves every
+// body and nothing else, as FetchToMirror does.
+type MirrorOptions struct {
+	// Client, if not nil, sends the requests, so that its cookies, such as
+	// those of a session logged in to, and its other settings apply. If
+	// nil, a client with DefaultTimeout sharing the package's connections
+	// is used.
+	Client *http.Client
+
+	// Accept, if not empty, lists the content types to save, as for
+	// FetchToMirrorAccept.
+	Accept []string
+
+	// Headers also saves the response header of each body saved, as
+	// "Key: value" lines sorted by key, in a file beside it named after it
+	// with ".headers" added: page.html.headers. Nothing is written for a
+	// body that is skipped.
+	Headers bool
+
+	// Gzip compresses each body as FileOptions.Gzip does, adding .gz to its
+	// name. The .headers file is named after the uncompressed body.
+	Gzip bool
+
+	// Manifest, if not nil, gets an entry for every URL fetched, whether
+	// its content was saved, skipped or could not be fetched.
+	Manifest *Manifest
+}
+
+// FetchToMirrorWith is FetchToMirror configured by opts. It also returns
+// the size of the content, before any compression.
+func FetchToMirrorWith(rawURL, root string, opts MirrorOptions) (name string, n int64, err error) {
+	e := ManifestEntry{URL: rawURL, Time: time.Now()}
+	defer func() {
+		if opts.Manifest != nil {
+			e.Path, e.Bytes = name, n
+			if err != nil {
+				e.Error = err.Error()
+			}
+			var se *StatusError
+			if errors.As(err, &se) {
+				e.Status = se.StatusCode
+			}
+			opts.Manifest.add(e)
+		}
+	}()
+	if opts.Client == nil {
+		opts.Client = clientWithTimeout(DefaultTimeout)
+	}
+	if len(opts.Accept) > 0 {
+		if r, err := headWith(opts.Client, rawURL); err == nil && r.StatusCode < 400 {
+			if ct := r.Header.Get("Content-Type"); ct != "" && !acceptsType(opts.Accept, ct) {
+				e.Status = r.StatusCode
+				return "", 0, &ContentTypeError{URL: rawURL, ContentType: ct}
+			}
+		}
+	}
+	return mirror(rawURL, root, opts, &e)
+}
+
+// mirror is FetchToMirrorWith without the HEAD request. It records the
+// status and checksum of the response in e.
+func mirror(rawURL, root string, opts MirrorOptions, e *ManifestEntry) (string, int64, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	ctx := context.Background()
+	resp, err := getWith(ctx, opts.Client, rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	e.Status = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		return "", 0, statusError(rawURL, resp)
+	}
+	if ct := resp.Header.Get("Content-Type"); len(opts.Accept) > 0 && !acceptsType(opts.Accept, ct) {
+		return "", 0, &ContentTypeError{URL: rawURL, ContentType: ct}
+	}
+	plain := filepath.Join(root, mirrorPath(u))
+	name := plain
+	if opts.Gzip {
+		name += ".gz"
+	}
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", 0, err
+	}
+	f, err := os.CreateTemp(dir, ".fetch-*")
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	n, err := saveBody(ctx, f, io.TeeReader(resp.Body, h), rawURL, opts.Client.Timeout, opts.Gzip) // h sees the uncompressed body
+	if err == nil {
+		err = f.Chmod(0o644) // CreateTemp makes the file private; FetchToFile's are not
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", 0, err
+	}
+	if opts.Headers {
+		if err := writeHeader(plain+".headers", resp.Header); err != nil {
+			return "", 0, err
+		}
+	}
+	e.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return name, n, nil
+}
+
+// writeHeader writes h to the file name as "Key: value" lines, sorted by
+// key, with one line for each value of a repeated field.
+func writeHeader(name string, h http.Header) error {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	return os.WriteFile(name, []byte(b.String()), 0o644)
+}
+
+// mirrorPath returns the path, relative to the root of a mirror, at which
+// FetchToMirror saves the content of u: the host, then the directories of
+// the URL path, then a file name that includes any query.
+func mirrorPath(u *url.URL) string {
+	p := path.Clean("/" + u.Path) // no .. can climb out of the mirror
+	if strings.HasSuffix(u.Path, "/") || p == "/" {
+		p = path.Join(p, "index.html")
+	}
+	dir, file := path.Split(p)
+	parts := []string{safeSegment(u.Host)}
+	for _, seg := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if seg != "" {
+			parts = append(parts, safeSegment(seg))
+		}
+	}
+	parts = append(parts, fileName(&url.URL{Path: file, RawQuery: u.RawQuery}))
+	return filepath.Join(parts...)
+}
+
+// safeSegment returns s made safe, as fileName does, for use as one part of
+// a path.
+func safeSegment(s string) string {
+	if len(s) > maxFileName {
+		s = s[:maxFileName]
+	}
+	s = sanitize(s)
+	if s == "" || s == "." || s == ".." {
+		s = "_" + s
+	}
+	return s
+}
+
+// fileName returns a file name for the content of u that is safe to use on
+// any file system: the slashes of the path become underscores, the query, if
+// any, is added before the extension, and every byte other than an ASCII
+// letter, digit, '.', '-' or '_' becomes '_'. An empty path gives index.html.
+func fileName(u *url.URL) string {
+	name := strings.Trim(u.Path, "/")
+	if name == "" {
+		name = "index.html"
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if u.RawQuery != "" {
+		base += "_" + u.RawQuery
+	}
+	if len(base) > maxFileName {
+		base = base[:maxFileName]
+	}
+	name = sanitize(base) + sanitize(ext)
+	if name == "." || name == ".." {
+		name = "_" + name
+	}
+	return name
+}
+
+// sanitize replaces every byte of s that is not safe in a file name with '_'.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		case r == '.' || r == '-' || r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// createUnique creates a new file called name in dir, or, if that exists,
+// the first free name of the form base-N.ext.
+func createUnique(dir, name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+}
+
+// writeFileAtomic writes data to the named file by way of a temporary file
+// in the same directory that is renamed into place, so that a reader, or
+// a later run after a crash, never sees the file half written.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
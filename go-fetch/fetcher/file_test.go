@@ -0,0 +1,245 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultFileName(t *testing.T) {
+	const rawURL = "http://example.com/a/b.html?q=1"
+	if got := DefaultFileName(rawURL, nil); got != "a_b_q_1.html" {
+		t.Errorf("DefaultFileName with no response = %q, want a_b_q_1.html", got)
+	}
+	for cd, want := range map[string]string{
+		`attachment; filename="report 1.pdf"`:        "report_1.pdf",
+		`attachment; filename="../../etc/passwd"`:    "passwd",
+		`attachment; filename="..\\..\\x\\evil.exe"`: "evil.exe",
+		`attachment; filename*=UTF-8''caf%C3%A9.txt`: "caf_.txt",
+		`attachment; filename=".bashrc"`:             "a_b_q_1.html",
+		`attachment; filename="dir/.hidden"`:         "a_b_q_1.html",
+		`attachment; filename*=UTF-8''a%00b.txt`:     "a_b_q_1.html",
+		`attachment; filename=".."`:                  "a_b_q_1.html",
+		`attachment; filename="dir/"`:                "dir",
+		`attachment`:                                 "a_b_q_1.html",
+		``:                                           "a_b_q_1.html",
+	} {
+		resp := &http.Response{Header: http.Header{"Content-Disposition": {cd}}}
+		if got := DefaultFileName(rawURL, resp); got != want {
+			t.Errorf("Content-Disposition %q: DefaultFileName = %q, want %q", cd, got, want)
+		}
+	}
+}
+
+func TestFetchToFileName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="data.csv"`)
+		w.Header().Set("X-Id", "42")
+		w.Write([]byte("a,b\n"))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	name, err := FetchToFile(srv.URL+"/download", dir)
+	if err != nil || filepath.Base(name) != "data.csv" {
+		t.Errorf("FetchToFile = %q, %v; want data.csv from Content-Disposition", name, err)
+	}
+
+	byID := func(rawURL string, resp *http.Response) string { return "id-" + resp.Header.Get("X-Id") }
+	name, _, err = FetchToFileWith(srv.URL+"/download", dir, FileOptions{Name: byID})
+	if b, _ := os.ReadFile(name); err != nil || filepath.Base(name) != "id-42" || string(b) != "a,b\n" {
+		t.Errorf("FetchToFileWith = %q, %v; want id-42 holding the body", name, err)
+	}
+
+	for _, bad := range []string{"", "../escape", `..\escape`, "sub/file", ".hidden", "a\x00b"} {
+		bad := bad
+		name, _, err := FetchToFileWith(srv.URL+"/download", dir, FileOptions{Name: func(string, *http.Response) string { return bad }})
+		if err == nil {
+			t.Errorf("Name %q: saved %s, want an error", bad, name)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("%d files in %s, want only data.csv and id-42", len(entries), dir)
+	}
+}
+
+func TestFetchToFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer srv.Close()
+	dir := filepath.Join(t.TempDir(), "new")
+
+	for _, want := range []string{"docs_page.html", "docs_page-1.html"} {
+		name, err := FetchToFile(srv.URL+"/docs/page.html", dir)
+		if b, _ := os.ReadFile(name); err != nil || filepath.Base(name) != want || string(b) != "content of /docs/page.html" {
+			t.Errorf("FetchToFile = %q, %v; want %s holding the content", name, err, want)
+		}
+	}
+	var se *StatusError
+	if name, err := FetchToFile(srv.URL+"/missing", dir); !errors.As(err, &se) || se.StatusCode != http.StatusNotFound {
+		t.Errorf("FetchToFile of a 404 = %q, %v; want a *StatusError", name, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("%d files in %s, want 2", len(entries), dir)
+	}
+}
+
+func TestMirrorPath(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"http://example.com", "example.com/index.html"},
+		{"http://example.com/", "example.com/index.html"},
+		{"http://example.com/a/b/c.html", "example.com/a/b/c.html"},
+		{"http://example.com/a/b/", "example.com/a/b/index.html"},
+		{"http://example.com/a/c.html?q=1", "example.com/a/c_q_1.html"},
+		{"http://example.com/../../etc/passwd", "example.com/etc/passwd"},
+		{"http://example.com:8080/x y/z", "example.com_8080/x_y/z"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mirrorPath(u); got != filepath.FromSlash(tt.want) {
+			t.Errorf("mirrorPath(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFetchToMirror(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer srv.Close()
+	root := t.TempDir()
+	u, _ := url.Parse(srv.URL)
+
+	for _, p := range []string{"/", "/docs/page.html", "/docs/page.html"} {
+		name, err := FetchToMirror(srv.URL+p, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := filepath.Join(root, mirrorPath(&url.URL{Host: u.Host, Path: p}))
+		if b, _ := os.ReadFile(name); name != want || string(b) != "content of "+p {
+			t.Errorf("FetchToMirror(%s) = %q holding %q; want %q", p, name, b, want)
+		}
+	}
+}
+
+func TestFetchToMirrorHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Zeta", "last")
+		w.Header().Add("X-Alpha", "1")
+		w.Header().Add("X-Alpha", "2")
+		w.Write([]byte("page"))
+	}))
+	defer srv.Close()
+	root := t.TempDir()
+
+	name, _, err := FetchToMirrorWith(srv.URL+"/page.html", root, MirrorOptions{Headers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(name + ".headers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := string(b)
+	if !strings.Contains(h, "X-Alpha: 1\nX-Alpha: 2\n") || !strings.HasSuffix(h, "X-Zeta: last\n") || strings.Index(h, "Content-Type: text/html\n") > strings.Index(h, "X-Alpha") {
+		t.Errorf("%s.headers = %q, want the fields sorted by key, one line per value", name, h)
+	}
+
+	if _, _, err := FetchToMirrorWith(srv.URL+"/skipped.html", root, MirrorOptions{Headers: true, Accept: []string{"image/*"}}); err == nil {
+		t.Error("saved a page of an unaccepted type")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(name), "skipped*")); len(matches) != 0 {
+		t.Errorf("skipped page left %q", matches)
+	}
+}
+
+func TestFetchToFileGzip(t *testing.T) {
+	content := strings.Repeat("compressible text ", 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	name, n, err := FetchToFileWith(srv.URL+"/a.txt", dir, FileOptions{Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(name) != "a.txt.gz" || n != int64(len(content)) {
+		t.Errorf("FetchToFileWith = %q, %d bytes; want a.txt.gz and the uncompressed size %d", name, n, len(content))
+	}
+	if got := gunzipFile(t, name); got != content {
+		t.Errorf("%s holds %d bytes uncompressed, want the %d fetched", name, len(got), len(content))
+	}
+
+	name, _, err = FetchToMirrorWith(srv.URL+"/b.txt", dir, MirrorOptions{Gzip: true, Headers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gunzipFile(t, name); !strings.HasSuffix(name, "b.txt.gz") || got != content {
+		t.Errorf("FetchToMirrorWith saved %q holding %d bytes uncompressed; want b.txt.gz and the content", name, len(got))
+	}
+	if _, err := os.Stat(strings.TrimSuffix(name, ".gz") + ".headers"); err != nil {
+		t.Errorf("headers of a compressed body: %v; want them named after the uncompressed one", err)
+	}
+}
+
+// gunzipFile returns the uncompressed content of the gzip file name.
+func gunzipFile(t *testing.T, name string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,102 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// fileTransport answers GET and HEAD requests for file:// URLs from the
+// local file system, so that fixtures can be fetched without a server.
+// Errors opening the file become responses with the status a server would
+// give, such as 404 Not Found for a missing file, so that they are reported
+// as for HTTP URLs. A redirect to a file:// URL from any other scheme is
+// refused, so that a remote server cannot have local files read.
+type fileTransport struct{}
+
+func (fileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if req.Response != nil && req.Response.Request.URL.Scheme != "file" {
+		return nil, fmt.Errorf("refusing redirect from %s to a file URL", req.Response.Request.URL.Scheme)
+	}
+	if h := req.URL.Hostname(); h != "" && h != "localhost" {
+		return nil, fmt.Errorf("file URL names a remote host %q", h)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return fileResponse(req, http.StatusMethodNotAllowed), nil
+	}
+	f, err := os.Open(filepath.FromSlash(req.URL.Path))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fileResponse(req, http.StatusNotFound), nil
+	case errors.Is(err, fs.ErrPermission):
+		return fileResponse(req, http.StatusForbidden), nil
+	case err != nil:
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.IsDir() {
+		f.Close()
+		return fileResponse(req, http.StatusForbidden), nil // no directory listings
+	}
+	resp := fileResponse(req, http.StatusOK)
+	resp.ContentLength = fi.Size()
+	resp.Header.Set("Content-Length", strconv.FormatInt(fi.Size(), 10))
+	resp.Header.Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+	if ct := mime.TypeByExtension(filepath.Ext(fi.Name())); ct != "" {
+		resp.Header.Set("Content-Type", ct)
+	}
+	if req.Method == http.MethodHead {
+		f.Close()
+	} else {
+		resp.Body = f
+	}
+	return resp, nil
+}
+
+// fileResponse returns an empty response to req with the given status.
+func fileResponse(req *http.Request, code int) *http.Response {
+	return &http.Response{
+		Status:     strconv.Itoa(code) + " " + http.StatusText(code),
+		StatusCode: code,
+		Proto:      "HTTP/1.0",
+		ProtoMajor: 1,
+		Header:     http.Header{"Date": {time.Now().UTC().Format(http.TimeFormat)}},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.html": "first", "b.png": "second", "c": "third"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	values := url.Values{"title": {"two files"}}
+	files := map[string][]string{
+		"docs":  {filepath.Join(dir, "b.png"), filepath.Join(dir, "a.html")},
+		"extra": {filepath.Join(dir, "c")},
+	}
+	body, ct, err := MultipartBody(values, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || mt != "multipart/form-data" {
+		t.Fatalf("Content-Type %q: %v", ct, err)
+	}
+	type part struct{ field, file, typ, content string }
+	var got []part
+	r := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p)
+		got = append(got, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(b)})
+	}
+	want := []part{
+		{"title", "", "", "two files"},
+		{"docs", "b.png", "image/png", "second"},
+		{"docs", "a.html", "text/html; charset=utf-8", "first"},
+		{"extra", "c", "application/octet-stream", "third"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d parts %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("part %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, _, err := MultipartBody(nil, map[string][]string{"f": {filepath.Join(dir, "missing")}}); err == nil {
+		t.Error("MultipartBody with a missing file succeeded")
+	}
+}
+
+func TestFormBody(t *testing.T) {
+	body, ct := FormBody(url.Values{"q": {"a b&c"}, "n": {"1", "2"}})
+	if ct != "application/x-www-form-urlencoded" || string(body) != "n=1&n=2&q=a+b%26c" {
+		t.Errorf("FormBody = %q, %q", body, ct)
+	}
+}
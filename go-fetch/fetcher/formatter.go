@@ -0,0 +1,196 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A Formatter presents the Results of a batch. Format is called with each
+// result as it completes, and Flush once with the summary of the batch
+// when all are done; a formatter may write as it goes or hold the results
+// until Flush. Batches call OnResult from one goroutine at a time unless
+// ConcurrentOnResult is set, so formatters need not be safe for concurrent
+// use.
+type Formatter interface {
+	Format(r Result) error
+	Flush(s Summary) error
+}
+
+// A TextFormatter writes each result as a line for people to read: its
+// time, status, size and URL, with the Location of a redirect, or its
+// error. Flush writes the totals.
+type TextFormatter struct {
+	w io.Writer
+}
+
+// NewTextFormatter returns a TextFormatter writing to w.
+func NewTextFormatter(w io.Writer) *TextFormatter {
+	return &TextFormatter{w: w}
+}
+
+func (f *TextFormatter) Format(r Result) error {
+	if r.Err != nil {
+		_, err := fmt.Fprintln(f.w, r.err())
+		return err
+	}
+	loc := ""
+	if r.StatusCode >= 300 && r.StatusCode < 400 {
+		loc = " -> " + r.Header.Get("Location") // a redirect that was not followed
+	}
+	_, err := fmt.Fprintf(f.w, "%8s  %d  %9s  %s%s\n",
+		FormatDuration(r.Elapsed), r.StatusCode, FormatBytes(r.Bytes), RedactURL(r.URL), loc)
+	return err
+}
+
+func (f *TextFormatter) Flush(s Summary) error {
+	_, err := fmt.Fprintf(f.w, "%d succeeded, %d failed, %s, mean %s, p95 %s\n",
+		s.Succeeded, s.Failed, FormatBytes(s.TotalBytes), FormatDuration(s.Mean), FormatDuration(s.P95))
+	return err
+}
+
+// A jsonResult is the form in which JSONFormatter writes a Result.
+type jsonResult struct {
+	URL       string  `json:"url"`
+	Label     string  `json:"label,omitempty"`    // the label given to the URL, if any
+	Status    int     `json:"status"`             // 0 if no response was received
+	Bytes     int64   `json:"bytes"`              // body bytes read
+	SHA256    string  `json:"sha256,omitempty"`   // hex digest of the body, if a checksum was asked for
+	ElapsedMS float64 `json:"elapsed_ms"`         // time taken, in milliseconds
+	Error     string  `json:"error,omitempty"`    // the error, if the fetch returned one
+	Location  string  `json:"location,omitempty"` // where a 3xx response that was not followed points
+	Proto     string  `json:"proto,omitempty"`    // protocol of the response, such as HTTP/2.0
+}
+
+func newJSONResult(r Result) jsonResult {
+	jr := jsonResult{
+		URL:       RedactURL(r.URL), // keep any password out of the output
+		Label:     r.Label,
+		Status:    r.StatusCode,
+		Proto:     r.Proto,
+		Bytes:     r.Bytes,
+		SHA256:    r.SHA256,
+		ElapsedMS: float64(r.Elapsed) / float64(time.Millisecond),
+	}
+	if r.Err != nil {
+		jr.Error = r.Err.Error()
+	}
+	if r.StatusCode >= 300 && r.StatusCode < 400 {
+		jr.Location = r.Header.Get("Location")
+	}
+	return jr
+}
+
+// A JSONFormatter writes the results as one JSON array of objects with
+// the fields url, label, status, bytes, sha256, elapsed_ms, error, location
+// and proto. It holds the results until Flush, which writes the array, so
+// that the output is always a complete document.
+type JSONFormatter struct {
+	w       io.Writer
+	results []jsonResult
+}
+
+// NewJSONFormatter returns a JSONFormatter writing to w.
+func NewJSONFormatter(w io.Writer) *JSONFormatter {
+	return &JSONFormatter{w: w, results: []jsonResult{}} // not nil, so that no results print as [] rather than null
+}
+
+func (f *JSONFormatter) Format(r Result) error {
+	f.results = append(f.results, newJSONResult(r))
+	return nil
+}
+
+func (f *JSONFormatter) Flush(Summary) error {
+	enc := json.NewEncoder(f.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f.results)
+}
+
+// An NDJSONFormatter writes each result as it completes as one JSON object
+// on a line of its own, with the fields JSONFormatter uses, so that the
+// output can be streamed into log pipelines and each line parsed alone.
+// Nothing is held, however many results there are.
+type NDJSONFormatter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONFormatter returns an NDJSONFormatter writing to w.
+func NewNDJSONFormatter(w io.Writer) *NDJSONFormatter {
+	return &NDJSONFormatter{enc: json.NewEncoder(w)} // without indentation, Encode writes one line
+}
+
+func (f *NDJSONFormatter) Format(r Result) error {
+	return f.enc.Encode(newJSONResult(r))
+}
+
+func (f *NDJSONFormatter) Flush(Summary) error {
+	return nil
+}
+
+// A CSVFormatter writes the results as CSV, after a header row naming the
+// columns url, status, bytes, sha256, elapsed_ms and error.
+type CSVFormatter struct {
+	w      *csv.Writer
+	header bool // whether the header row has been written
+}
+
+// NewCSVFormatter returns a CSVFormatter writing to w.
+func NewCSVFormatter(w io.Writer) *CSVFormatter {
+	return &CSVFormatter{w: csv.NewWriter(w)}
+}
+
+func (f *CSVFormatter) Format(r Result) error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	jr := newJSONResult(r)
+	return f.w.Write([]string{
+		jr.URL,
+		strconv.Itoa(jr.Status),
+		strconv.FormatInt(jr.Bytes, 10),
+		jr.SHA256,
+		strconv.FormatFloat(jr.ElapsedMS, 'f', 3, 64),
+		jr.Error,
+	})
+}
+
+func (f *CSVFormatter) Flush(Summary) error {
+	if err := f.writeHeader(); err != nil { // even with no results
+		return err
+	}
+	f.w.Flush()
+	return f.w.Error()
+}
+
+func (f *CSVFormatter) writeHeader() error {
+	if f.header {
+		return nil
+	}
+	f.header = true
+	return f.w.Write([]string{"url", "status", "bytes", "sha256", "elapsed_ms", "error"})
+}
@@ -0,0 +1,129 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// formatterResults are a success, with a password in its URL, a redirect
+// that was not followed and a failure.
+var formatterResults = []Result{
+	{URL: "http://user:secret@a/1", StatusCode: 200, Bytes: 1536, Elapsed: 1500 * time.Microsecond},
+	{URL: "http://a/2", StatusCode: 301, Header: http.Header{"Location": {"/new"}}, Elapsed: 2 * time.Millisecond},
+	{URL: "http://a/3", Err: errors.New("refused")},
+}
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(*bytes.Buffer) Formatter
+		want string
+	}{
+		{"text", func(b *bytes.Buffer) Formatter { return NewTextFormatter(b) }, "" +
+			"   1.5ms  200     1.5 KB  http://user:xxxxx@a/1\n" +
+			"     2ms  301        0 B  http://a/2 -> /new\n" +
+			"fetch http://a/3: refused\n" +
+			"2 succeeded, 1 failed, 1.5 KB, mean 1.8ms, p95 2ms\n"},
+		{"json", func(b *bytes.Buffer) Formatter { return NewJSONFormatter(b) }, `[
+  {
+    "url": "http://user:xxxxx@a/1",
+    "status": 200,
+    "bytes": 1536,
+    "elapsed_ms": 1.5
+  },
+  {
+    "url": "http://a/2",
+    "status": 301,
+    "bytes": 0,
+    "elapsed_ms": 2,
+    "location": "/new"
+  },
+  {
+    "url": "http://a/3",
+    "status": 0,
+    "bytes": 0,
+    "elapsed_ms": 0,
+    "error": "refused"
+  }
+]
+`},
+		{"csv", func(b *bytes.Buffer) Formatter { return NewCSVFormatter(b) }, "" +
+			"url,status,bytes,sha256,elapsed_ms,error\n" +
+			"http://user:xxxxx@a/1,200,1536,,1.500,\n" +
+			"http://a/2,301,0,,2.000,\n" +
+			"http://a/3,0,0,,0.000,refused\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		f := tt.new(&buf)
+		for _, r := range formatterResults {
+			if err := f.Format(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.Flush(Summarize(formatterResults)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s formatter wrote:\n%s\nwant:\n%s", tt.name, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestFormattersEmpty(t *testing.T) {
+	var jsonOut, csvOut bytes.Buffer
+	if err := NewJSONFormatter(&jsonOut).Flush(Summary{}); err != nil || jsonOut.String() != "[]\n" {
+		t.Errorf("JSONFormatter with no results wrote %q, %v; want an empty array", jsonOut.String(), err)
+	}
+	if err := NewCSVFormatter(&csvOut).Flush(Summary{}); err != nil || csvOut.String() != "url,status,bytes,sha256,elapsed_ms,error\n" {
+		t.Errorf("CSVFormatter with no results wrote %q, %v; want the header row", csvOut.String(), err)
+	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewNDJSONFormatter(&buf)
+	for i, r := range formatterResults {
+		if err := f.Format(r); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != i+1 {
+			t.Fatalf("after %d results, %d lines written; want each written as it comes", i+1, lines)
+		}
+	}
+	if err := f.Flush(Summarize(formatterResults)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"url":"http://user:xxxxx@a/1","status":200,"bytes":1536,"elapsed_ms":1.5}` + "\n" +
+		`{"url":"http://a/2","status":301,"bytes":0,"elapsed_ms":2,"location":"/new"}` + "\n" +
+		`{"url":"http://a/3","status":0,"bytes":0,"elapsed_ms":0,"error":"refused"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("NDJSONFormatter wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
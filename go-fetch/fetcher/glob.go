@@ -0,0 +1,175 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxExpansions is the most URLs ExpandURL will return for one pattern.
+const MaxExpansions = 100000
+
+// ExpandURL expands the patterns in the URL pattern into the URLs they
+// stand for, as curl's globbing does. Two kinds of pattern are recognized:
+//
+//   - {a,b,c}, a list of alternatives, of which each is used in turn;
+//   - [first-last] or [first-last:step], a range of numbers or of letters,
+//     such as [1-100], [a-z] or [0-100:10]. A first number with leading
+//     zeros pads every number to its width, so [001-100] gives 001, 002
+//     and so on up to 100.
+//
+// A URL may hold several patterns, and then every combination of them is
+// expanded, with the last varying fastest: http://h/{a,b}/[1-2] gives
+// http://h/a/1, http://h/a/2, http://h/b/1 and http://h/b/2. Outside a
+// pattern, a backslash before {, }, [ or ] makes it an ordinary character.
+// Brackets that hold no range, as in an IPv6 host such as http://[::1]/,
+// are left as they are, and a URL with no patterns expands to itself.
+//
+// It is an error for a list to be unclosed, for a range to run backwards
+// or for the expansion to give more than MaxExpansions URLs.
+func ExpandURL(pattern string) ([]string, error) {
+	var parts [][]string // the choices for each piece of the pattern in turn
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			parts = append(parts, []string{lit.String()})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern) && strings.IndexByte("{}[]", pattern[i+1]) >= 0:
+			i++
+			lit.WriteByte(pattern[i])
+		case c == '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("URL pattern %q: unclosed {", pattern)
+			}
+			flush()
+			parts = append(parts, strings.Split(pattern[i+1:i+end], ","))
+			i += end
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				lit.WriteByte(c)
+				continue
+			}
+			values, ok, err := expandRange(pattern[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("URL pattern %q: %v", pattern, err)
+			}
+			if !ok {
+				lit.WriteByte(c) // not a range: an IPv6 address or the like
+				continue
+			}
+			flush()
+			parts = append(parts, values)
+			i += end
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	flush()
+
+	total := 1
+	for _, p := range parts {
+		if total *= len(p); total > MaxExpansions {
+			return nil, fmt.Errorf("URL pattern %q: expands to more than %d URLs", pattern, MaxExpansions)
+		}
+	}
+	urls := []string{""}
+	for _, p := range parts {
+		next := make([]string, 0, len(urls)*len(p))
+		for _, prefix := range urls {
+			for _, s := range p {
+				next = append(next, prefix+s)
+			}
+		}
+		urls = next
+	}
+	return urls, nil
+}
+
+// expandRange returns the values of the range spec, the text between the
+// brackets of a [first-last:step] pattern. It reports false if spec is not
+// a range at all, and an error if it is one but cannot be expanded.
+func expandRange(spec string) ([]string, bool, error) {
+	step := 1
+	if i := strings.LastIndexByte(spec, ':'); i >= 0 {
+		n, err := strconv.Atoi(spec[i+1:])
+		if err != nil {
+			return nil, false, nil // as in [::1]
+		}
+		if n <= 0 {
+			return nil, true, fmt.Errorf("range [%s]: step must be positive", spec)
+		}
+		step, spec = n, spec[:i]
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found || first == "" || last == "" {
+		return nil, false, nil
+	}
+	if isLetter(first) && isLetter(last) {
+		a, b := first[0], last[0]
+		if a > b || (a <= 'Z') != (b <= 'Z') {
+			return nil, true, fmt.Errorf("range [%s] runs backwards or mixes cases", spec)
+		}
+		var values []string
+		for c := int(a); c <= int(b); c += step {
+			values = append(values, string(rune(c)))
+		}
+		return values, true, nil
+	}
+	a, err1 := strconv.Atoi(first)
+	b, err2 := strconv.Atoi(last)
+	if err1 != nil || err2 != nil || a < 0 {
+		return nil, false, nil
+	}
+	if a > b {
+		return nil, true, fmt.Errorf("range [%s] runs backwards", spec)
+	}
+	if (b-a)/step >= MaxExpansions {
+		return nil, true, fmt.Errorf("range [%s] has more than %d values", spec, MaxExpansions)
+	}
+	width := 0
+	if len(first) > 1 && first[0] == '0' {
+		width = len(first) // zero-padded
+	}
+	var values []string
+	for n := a; ; n += step {
+		values = append(values, fmt.Sprintf("%0*d", width, n))
+		if n > b-step {
+			break // checked before adding, which would overflow near math.MaxInt
+		}
+	}
+	return values, true, nil
+}
+
+// isLetter reports whether s is a single ASCII letter.
+func isLetter(s string) bool {
+	return len(s) == 1 && ('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z')
+}
@@ -0,0 +1,70 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandURLRanges(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		want    []string
+	}{
+		{"http://x/[1-3]", []string{"http://x/1", "http://x/2", "http://x/3"}},
+		{"http://x/[08-10]", []string{"http://x/08", "http://x/09", "http://x/10"}},
+		{"http://x/[0-10:5]", []string{"http://x/0", "http://x/5", "http://x/10"}},
+		{"http://x/[1-4:2]", []string{"http://x/1", "http://x/3"}},
+		{"http://x/[a-c]", []string{"http://x/a", "http://x/b", "http://x/c"}},
+		{"http://x/[9223372036854775806-9223372036854775807]",
+			[]string{"http://x/9223372036854775806", "http://x/9223372036854775807"}},
+		{"http://x/[9223372036854775807-9223372036854775807]", []string{"http://x/9223372036854775807"}},
+		{"http://x/[1-9223372036854775807:9223372036854775807]", []string{"http://x/1"}},
+		{"http://x/[9223372036854775800-9223372036854775807:3]",
+			[]string{"http://x/9223372036854775800", "http://x/9223372036854775803", "http://x/9223372036854775806"}},
+	} {
+		got, err := ExpandURL(c.pattern)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ExpandURL(%q) = %q, %v; want %q", c.pattern, got, err, c.want)
+		}
+	}
+}
+
+func TestExpandURLLimits(t *testing.T) {
+	for _, pattern := range []string{
+		"http://x/[0-9223372036854775807]",
+		"http://x/[0-100000]",
+		"http://x/[1-1000]/[1-1000]",
+		"http://x/[5-1]",
+		"http://x/[1-5:0]",
+	} {
+		if got, err := ExpandURL(pattern); err == nil {
+			t.Errorf("ExpandURL(%q) gave %d URLs, want an error", pattern, len(got))
+		}
+	}
+	if got, err := ExpandURL("http://x/[1-100000]"); err != nil || len(got) != MaxExpansions || !strings.HasSuffix(got[len(got)-1], "/100000") {
+		t.Errorf("ExpandURL of exactly MaxExpansions values = %d URLs, %v", len(got), err)
+	}
+}
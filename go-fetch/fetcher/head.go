@@ -0,0 +1,44 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/http"
+)
+
+// Head issues a HEAD request for url and reports the status, header and
+// declared Content-Length of the response without downloading the body.
+// It is much cheaper than a GET for checking that URLs exist.
+func Head(url string) (*Result, error) {
+	return headWith(clientWithTimeout(DefaultTimeout), url)
+}
+
+// headWith is like Head but sends the request with client.
+func headWith(client *http.Client, url string) (*Result, error) {
+	req, err := newRequest(context.Background(), http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetchRequest(client, req)
+}
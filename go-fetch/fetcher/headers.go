@@ -0,0 +1,68 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import "net/http"
+
+// FetchWithHeaders is like FetchResponse but sets each of headers on the
+// request, for example Authorization for an authenticated API. A
+// User-Agent given in headers replaces DefaultUserAgent.
+func FetchWithHeaders(url string, headers map[string]string) (*Result, error) {
+	return FetchMethod(http.MethodGet, url, nil, headers)
+}
+
+// A headerTransport is a RoundTripper setting the headers of
+// ClientOptions.Headers on the requests it sends with rt. As net/http does
+// for headers set on a request, it leaves the credentials out of a request
+// redirected to another host.
+type headerTransport struct {
+	rt      http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	offHost := false // redirected away from the host first asked for
+	for r := req; r.Response != nil && r.Response.Request != nil; r = r.Response.Request {
+		offHost = r.Response.Request.URL.Host != req.URL.Host
+	}
+	req = req.Clone(req.Context()) // a RoundTripper must not change the request it is given
+	for k, v := range t.headers {
+		if offHost && sensitiveHeader(k) {
+			continue
+		}
+		if have := req.Header.Get(k); have == "" || http.CanonicalHeaderKey(k) == "User-Agent" && have == DefaultUserAgent {
+			req.Header.Set(k, v)
+		}
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// sensitiveHeader reports whether the header k carries credentials, which
+// must not follow a redirect to another host.
+func sensitiveHeader(k string) bool {
+	switch http.CanonicalHeaderKey(k) {
+	case "Authorization", "Www-Authenticate", "Cookie", "Cookie2":
+		return true
+	}
+	return false
+}
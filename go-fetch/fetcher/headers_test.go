@@ -0,0 +1,89 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientHeaders(t *testing.T) {
+	var got []http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		if r.URL.Path == "/away" {
+			http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(ClientOptions{Headers: map[string]string{
+		"Authorization": "Bearer secret",
+		"X-Team":        "infra",
+		"User-Agent":    "custom/1.0",
+	}})
+
+	if _, err := FetchBatchError([]string{srv.URL}, BatchOptions{Client: client, Headers: map[string]string{"X-Team": "mine"}}); err != nil {
+		t.Fatal(err)
+	}
+	h := got[0]
+	if h.Get("Authorization") != "Bearer secret" || h.Get("User-Agent") != "custom/1.0" || h.Get("X-Team") != "mine" {
+		t.Errorf("request headers = %v, want the client's Authorization and User-Agent and the batch's X-Team", h)
+	}
+
+	got = nil
+	if _, err := FetchBatchError([]string{srv.URL + "/away"}, BatchOptions{Client: client}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want 2", len(got))
+	}
+	if a := got[1].Get("Authorization"); a != "" || got[1].Get("X-Team") != "infra" {
+		t.Errorf("after a redirect to another host, Authorization = %q and X-Team = %q, want none and infra", a, got[1].Get("X-Team"))
+	}
+}
+
+func TestFetchWithHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization") + "|" + r.Header.Get("User-Agent")))
+	}))
+	defer srv.Close()
+	r, err := FetchWithHeaders(srv.URL, map[string]string{"Authorization": "Bearer t", "User-Agent": "probe/2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Body) != "Bearer t|probe/2" {
+		t.Errorf("FetchWithHeaders = %q, want the headers given", r.Body)
+	}
+	if r, err = FetchWithHeaders(srv.URL, nil); err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Body) != "|"+DefaultUserAgent {
+		t.Errorf("FetchWithHeaders with none = %q, want only the default User-Agent", r.Body)
+	}
+}
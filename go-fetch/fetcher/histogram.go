@@ -0,0 +1,92 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// histogramWidth is the length of the longest bar drawn by WriteHistogram.
+const histogramWidth = 40
+
+// WriteHistogram draws on w an ASCII histogram of the latencies of the
+// successful fetches among results, in n buckets, one bar to a line. The
+// buckets span from the fastest fetch to the slowest, each bound a fixed
+// multiple of the one before, so that the tail shows up as clearly as the
+// bulk. Each line gives the bucket's upper bound, its bar and its count.
+// Nothing is drawn if no fetch succeeded.
+func WriteHistogram(w io.Writer, results []Result, n int) error {
+	var latencies []time.Duration
+	for _, r := range results {
+		if !r.Failed() {
+			latencies = append(latencies, r.Elapsed)
+		}
+	}
+	if len(latencies) == 0 {
+		return nil
+	}
+	lo, hi := latencies[0], latencies[0]
+	for _, d := range latencies {
+		if d < lo {
+			lo = d
+		}
+		if d > hi {
+			hi = d
+		}
+	}
+	if lo <= 0 {
+		lo = 1 // logarithms need a positive start
+	}
+	if n < 1 || hi <= lo {
+		n = 1
+	}
+	ratio := math.Pow(float64(hi)/float64(lo), 1/float64(n))
+	bounds := make([]time.Duration, n)
+	for i := range bounds {
+		bounds[i] = time.Duration(float64(lo) * math.Pow(ratio, float64(i+1)))
+	}
+	bounds[n-1] = hi // exactly, whatever the rounding
+	counts := make([]int, n)
+	most := 0
+	for _, d := range latencies {
+		i := 0
+		for i < n-1 && d > bounds[i] {
+			i++
+		}
+		counts[i]++
+		if counts[i] > most {
+			most = counts[i]
+		}
+	}
+	bw := bufio.NewWriter(w)
+	for i, b := range bounds {
+		bar := strings.Repeat("#", (counts[i]*histogramWidth+most-1)/most) // rounded up, so that no count shows as nothing
+		fmt.Fprintf(bw, "%10s  %-*s  %d\n", "<= "+FormatDuration(b), histogramWidth, bar, counts[i])
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,59 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHistogram(t *testing.T) {
+	var results []Result
+	for _, ms := range []int{1, 1, 1, 10, 100} {
+		results = append(results, Result{StatusCode: 200, Elapsed: time.Duration(ms) * time.Millisecond})
+	}
+	results = append(results, Result{Err: errors.New("refused"), Elapsed: time.Hour})
+
+	var buf bytes.Buffer
+	if err := WriteHistogram(&buf, results, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("%10s  %-40s  4\n%10s  %-40s  1\n",
+		"<= 10ms", strings.Repeat("#", 40), "<= 100ms", strings.Repeat("#", 10))
+	if buf.String() != want {
+		t.Errorf("WriteHistogram drew:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteHistogram(&buf, results[:3], 10); err != nil || buf.String() != fmt.Sprintf("%10s  %-40s  3\n", "<= 1ms", strings.Repeat("#", 40)) {
+		t.Errorf("WriteHistogram of equal latencies drew %q, %v; want one bucket", buf.String(), err)
+	}
+	buf.Reset()
+	if err := WriteHistogram(&buf, results[5:], 10); err != nil || buf.Len() != 0 {
+		t.Errorf("WriteHistogram of failures only drew %q, %v; want nothing", buf.String(), err)
+	}
+}
@@ -0,0 +1,79 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// hostSemaphores limits the fetches in flight to each host.
+type hostSemaphores struct {
+	limit int
+	mu    sync.Mutex
+	sems  map[string]chan struct{} // counting semaphore per host, made on first use
+}
+
+// newHostSemaphores returns semaphores allowing limit fetches per host, or
+// nil, which imposes no limit, if limit <= 0.
+func newHostSemaphores(limit int) *hostSemaphores {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostSemaphores{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+// acquire waits for a free slot for rawURL's host and returns the function
+// that frees it. It reports false, having acquired nothing, if h is nil or
+// ctx is done first. URLs that do not parse share one pool of slots.
+func (h *hostSemaphores) acquire(ctx context.Context, rawURL string) (release func(), ok bool) {
+	if h == nil {
+		return nil, false
+	}
+	host := hostKey(rawURL)
+	h.mu.Lock()
+	sem, found := h.sems[host]
+	if !found {
+		sem = make(chan struct{}, h.limit)
+		h.sems[host] = sem
+	}
+	h.mu.Unlock()
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// hostKey returns the host, with any port, of rawURL in lower case, or ""
+// if rawURL does not parse.
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
@@ -0,0 +1,74 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"sync"
+)
+
+// FetchInsecure is like FetchResponse but does not verify the server's
+// certificate chain or host name, so that internal hosts with self-signed
+// certificates can be fetched.
+//
+// This is UNSAFE: anyone able to intercept the connection can impersonate
+// the server and read or alter the content. Use it only for hosts you
+// control, never for anything carrying credentials. Every other function in
+// this package verifies certificates.
+//
+// If Transport has been set to something other than an *http.Transport,
+// FetchInsecure uses it as it is, having no TLS settings to change.
+func FetchInsecure(url string) (*Result, error) {
+	client := &http.Client{Timeout: DefaultTimeout, Transport: insecureTransport()}
+	return fetchResponse(context.Background(), client, url)
+}
+
+// insecure holds the transport FetchInsecure uses: a copy of base, the
+// *http.Transport that transport() returned, with verification off. It is
+// shared by every call, so that they reuse connections, and made again
+// only if transport() changes.
+var insecure struct {
+	mu   sync.Mutex
+	base *http.Transport
+	t    *http.Transport
+}
+
+// insecureTransport returns transport() with certificate verification
+// turned off, if it is an *http.Transport, or else unchanged.
+func insecureTransport() http.RoundTripper {
+	rt := transport()
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	insecure.mu.Lock()
+	defer insecure.mu.Unlock()
+	if insecure.base != base {
+		t := base.Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		insecure.base, insecure.t = base, t
+	}
+	return insecure.t
+}
@@ -0,0 +1,69 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// A LengthError reports that a response body ended before the number of
+// bytes its Content-Length declared, as when a proxy or server closes the
+// connection early. It is found inside the *PartialReadError reporting the
+// failed read, so that a truncated body is never taken for a whole one.
+type LengthError struct {
+	Expected int64 // the declared Content-Length
+	Got      int64 // the bytes that arrived, before any decompression
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("body truncated: expected %d bytes, got %d", e.Expected, e.Got)
+}
+
+// checkLength arranges for reading the body of resp to fail with a
+// *LengthError if it ends short of its Content-Length. It does nothing if
+// the length was not declared.
+func checkLength(resp *http.Response) {
+	if resp.ContentLength > 0 {
+		resp.Body = &lengthBody{body: resp.Body, want: resp.ContentLength}
+	}
+}
+
+// A lengthBody counts the bytes read from body, and replaces the error
+// reporting that it ended early with a *LengthError.
+type lengthBody struct {
+	body      io.ReadCloser
+	want, got int64
+}
+
+func (b *lengthBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.got += int64(n)
+	if err == io.ErrUnexpectedEOF || err == io.EOF && b.got < b.want {
+		err = &LengthError{Expected: b.want, Got: b.got}
+	}
+	return n, err
+}
+
+func (b *lengthBody) Close() error { return b.body.Close() }
@@ -0,0 +1,68 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLengthError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("ten bytes!"))
+	}))
+	defer srv.Close()
+
+	var le *LengthError
+	var pe *PartialReadError
+	_, err := FetchResponse(srv.URL)
+	if !errors.As(err, &le) || le.Expected != 100 || le.Got != 10 || !errors.As(err, &pe) {
+		t.Errorf("FetchResponse of a truncated body = %v; want a *LengthError of 10 of 100 bytes inside a *PartialReadError", err)
+	}
+	if r := FetchBatch([]string{srv.URL}, BatchOptions{}); !errors.As(r[0].Err, &le) || !r[0].Failed() {
+		t.Errorf("FetchBatch of a truncated body: err %v, want a *LengthError", r[0].Err)
+	}
+}
+
+func TestLengthBody(t *testing.T) {
+	for _, tt := range []struct {
+		body    string
+		want    int64
+		wantErr bool
+	}{
+		{"complete", 8, false},
+		{"short", 8, true},
+	} {
+		b := &lengthBody{body: io.NopCloser(strings.NewReader(tt.body)), want: tt.want}
+		_, err := io.ReadAll(b)
+		var le *LengthError
+		if got := errors.As(err, &le); got != tt.wantErr {
+			t.Errorf("reading %q declared as %d bytes: err %v, want a *LengthError %v", tt.body, tt.want, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"io"
+)
+
+// FetchLimited is like FetchTo but copies at most maxBytes bytes of the
+// content to w, so that a huge or endless body cannot exhaust memory or
+// disk. It reports whether the body was longer than maxBytes and so was
+// truncated.
+func FetchLimited(w io.Writer, url string, maxBytes int64) (n int64, truncated bool, err error) {
+	ctx := context.Background()
+	resp, err := get(ctx, url, DefaultTimeout)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	n, err = copyBody(ctx, w, io.LimitReader(resp.Body, maxBytes), url, DefaultTimeout)
+	if err != nil || n < maxBytes {
+		return n, false, err
+	}
+	var extra [1]byte
+	m, _ := io.ReadFull(resp.Body, extra[:]) // is there anything past the limit?
+	return n, m > 0, nil
+}
@@ -0,0 +1,75 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MaxLineLength is the longest line FetchLines will read; a longer one
+// stops it with an error.
+const MaxLineLength = 1 << 20
+
+// FetchLines fetches url and calls fn with each line of the body, without
+// its line ending, as soon as the line has arrived, so that endpoints that
+// stream newline-delimited records, such as logs or events, can be
+// consumed while they are open. If fn returns false FetchLines stops
+// reading and returns a nil error. It returns the number of lines passed
+// to fn.
+//
+// There is no time limit beyond ctx, since a stream may stay open
+// indefinitely. A 4xx or 5xx response is a *StatusError, and a line longer
+// than MaxLineLength is an error naming its number.
+func FetchLines(ctx context.Context, url string, fn func(line string) bool) (int, error) {
+	resp, err := getWith(ctx, &http.Client{Transport: transport()}, url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, statusError(url, resp)
+	}
+	input := bufio.NewScanner(resp.Body)
+	input.Buffer(make([]byte, 0, 64*1024), MaxLineLength)
+	n := 0
+	for input.Scan() {
+		n++
+		if !fn(input.Text()) {
+			return n, nil
+		}
+	}
+	switch err := input.Err(); {
+	case err == nil:
+		return n, nil
+	case errors.Is(err, bufio.ErrTooLong):
+		return n, fmt.Errorf("reading %s: line %d is longer than %d bytes", RedactURL(url), n+1, MaxLineLength)
+	case ctx.Err() != nil:
+		return n, ctx.Err()
+	default:
+		return n, fmt.Errorf("reading %s: after %d lines: %w", RedactURL(url), n, err)
+	}
+}
@@ -0,0 +1,77 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchLines(t *testing.T) {
+	seen := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stream":
+			io.WriteString(w, "first\r\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-seen: // the first line was handed over while the stream was open
+			case <-r.Context().Done():
+				return
+			}
+			io.WriteString(w, "second\nstop\nnever seen\n")
+		case "/long":
+			io.WriteString(w, "short\n"+strings.Repeat("x", MaxLineLength+1)+"\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	var lines []string
+	n, err := FetchLines(ctx, srv.URL+"/stream", func(line string) bool {
+		if len(lines) == 0 {
+			close(seen)
+		}
+		lines = append(lines, line)
+		return line != "stop"
+	})
+	if err != nil || n != 3 || strings.Join(lines, ",") != "first,second,stop" {
+		t.Errorf("FetchLines = %d, %v, saw %q; want first, second and stop", n, err, lines)
+	}
+
+	n, err = FetchLines(ctx, srv.URL+"/long", func(string) bool { return true })
+	if err == nil || n != 1 || !strings.Contains(err.Error(), "line 2 is longer") {
+		t.Errorf("FetchLines of an overlong line = %d, %v; want an error naming line 2", n, err)
+	}
+	var se *StatusError
+	if _, err := FetchLines(ctx, srv.URL+"/missing", func(string) bool { return true }); !errors.As(err, &se) {
+		t.Errorf("FetchLines of a 404 = %v, want a *StatusError", err)
+	}
+}
@@ -0,0 +1,121 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/http"
+)
+
+// linkCheckConcurrency is the number of URLs CheckLinks checks at once.
+const linkCheckConcurrency = 20
+
+// A LinkClass classifies the outcome of checking a link.
+type LinkClass int
+
+const (
+	LinkFailed      LinkClass = iota // no response: the request itself failed
+	LinkOK                           // 2xx
+	LinkRedirect                     // 3xx
+	LinkClientError                  // 4xx
+	LinkServerError                  // 5xx
+)
+
+var linkClassNames = [...]string{"failed", "ok", "redirect", "client error", "server error"}
+
+func (c LinkClass) String() string {
+	if c < 0 || int(c) >= len(linkClassNames) {
+		return "LinkClass(?)"
+	}
+	return linkClassNames[c]
+}
+
+// Broken reports whether a link of class c needs fixing.
+func (c LinkClass) Broken() bool {
+	return c == LinkFailed || c == LinkClientError || c == LinkServerError
+}
+
+// A LinkStatus is the result of checking one link.
+type LinkStatus struct {
+	URL        string
+	Class      LinkClass
+	StatusCode int    // 0 if the request failed
+	Location   string // where a redirect points
+	Err        error  // why the request failed, for LinkFailed
+}
+
+// CheckLinks checks each of urls with a HEAD request, several at a time,
+// and classifies the response. Redirects are not followed, so that they are
+// reported as such. A server that refuses HEAD (405 or 501) is asked again
+// with a GET. The statuses are returned in the same order as urls.
+func CheckLinks(urls []string) []LinkStatus {
+	client := &http.Client{
+		Transport: transport(),
+		Timeout:   DefaultTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse // report the redirect rather than follow it
+		},
+	}
+	opts := BatchOptions{Client: client, Concurrency: linkCheckConcurrency, Method: http.MethodHead}
+	results := fetchAll(context.Background(), urls, opts)
+
+	var retry []string // URLs whose server would not answer HEAD
+	var where []int    // their indices in urls
+	for i, r := range results {
+		if r.StatusCode == http.StatusMethodNotAllowed || r.StatusCode == http.StatusNotImplemented {
+			retry = append(retry, r.URL)
+			where = append(where, i)
+		}
+	}
+	if len(retry) > 0 {
+		opts.Method = http.MethodGet
+		for j, r := range fetchAll(context.Background(), retry, opts) {
+			results[where[j]] = r
+		}
+	}
+
+	statuses := make([]LinkStatus, len(results))
+	for i, r := range results {
+		statuses[i] = linkStatus(r)
+	}
+	return statuses
+}
+
+// linkStatus classifies the Result of checking a link.
+func linkStatus(r Result) LinkStatus {
+	s := LinkStatus{URL: r.URL, StatusCode: r.StatusCode, Err: r.Err}
+	switch {
+	case r.Err != nil || r.StatusCode == 0:
+		s.Class = LinkFailed
+	case r.StatusCode < 300:
+		s.Class = LinkOK
+	case r.StatusCode < 400:
+		s.Class = LinkRedirect
+		s.Location = r.Header.Get("Location")
+	case r.StatusCode < 500:
+		s.Class = LinkClientError
+	default:
+		s.Class = LinkServerError
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		case "/gone":
+			http.NotFound(w, r)
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		class    LinkClass
+		location string
+	}{
+		{"/ok", LinkOK, ""},
+		{"/moved", LinkRedirect, "/ok"},
+		{"/gone", LinkClientError, ""},
+		{"/nohead", LinkOK, ""},
+		{"/broken", LinkServerError, ""},
+	}
+	urls := make([]string, len(tests))
+	for i, tt := range tests {
+		urls[i] = srv.URL + tt.path
+	}
+	urls = append(urls, "http://127.0.0.1:1/refused")
+
+	statuses := CheckLinks(urls)
+	for i, tt := range tests {
+		s := statuses[i]
+		if s.URL != urls[i] || s.Class != tt.class || s.Location != tt.location {
+			t.Errorf("CheckLinks(%s) = %s, %v, Location %q; want %v, Location %q", tt.path, s.URL, s.Class, s.Location, tt.class, tt.location)
+		}
+	}
+	if s := statuses[len(tests)]; s.Class != LinkFailed || s.Err == nil || !s.Class.Broken() {
+		t.Errorf("CheckLinks(refused) = %v, %v; want a broken link with an error", s.Class, s.Err)
+	}
+	if LinkOK.Broken() || LinkRedirect.Broken() || LinkClass(9).String() != "LinkClass(?)" {
+		t.Errorf("LinkOK or LinkRedirect is broken, or LinkClass(9) = %q", LinkClass(9))
+	}
+}
@@ -0,0 +1,105 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A Level says how much SetLogger's logger is told.
+type Level int
+
+const (
+	LevelInfo  Level = 1 + iota // each request, its response status and time, redirects and retries
+	LevelDebug                  // all of that and the request and response headers
+)
+
+var (
+	logger   *log.Logger // nil means logging is off
+	logLevel Level
+)
+
+// SetLogger makes the package log what it does to l, at the given level,
+// for debugging failed fetches. Logging is off by default and if l is nil.
+// Set it before fetching, not while fetches are in progress.
+func SetLogger(l *log.Logger, level Level) {
+	logger, logLevel = l, level
+}
+
+// logf logs a line if logging is on at level or above.
+func logf(level Level, format string, args ...interface{}) {
+	if logger != nil && logLevel >= level {
+		logger.Printf(format, args...)
+	}
+}
+
+// logRequest logs that req is about to be sent.
+func logRequest(req *http.Request) {
+	logf(LevelInfo, "%s %s", req.Method, RedactURL(req.URL.String()))
+	logHeader("> ", req.Header)
+}
+
+// logResponse logs resp, the response to a request sent start, and the
+// redirects that led to it.
+func logResponse(resp *http.Response, start time.Time) {
+	if logger == nil {
+		return
+	}
+	var hops []*http.Request // the requests redirected from, newest first
+	for r := resp.Request; r.Response != nil; r = r.Response.Request {
+		hops = append(hops, r.Response.Request)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		from := hops[i]
+		to := resp.Request
+		if i > 0 {
+			to = hops[i-1]
+		}
+		logf(LevelInfo, "redirect %d %s -> %s", to.Response.StatusCode, RedactURL(from.URL.String()), RedactURL(to.URL.String()))
+	}
+	logf(LevelInfo, "%s %s in %s", resp.Status, RedactURL(resp.Request.URL.String()), FormatDuration(time.Since(start)))
+	logHeader("< ", resp.Header)
+}
+
+// logHeader logs h, one field to a line behind prefix, at LevelDebug.
+func logHeader(prefix string, h http.Header) {
+	if logger == nil || logLevel < LevelDebug {
+		return
+	}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := strings.Join(h[k], ", ")
+		if k == "Authorization" || k == "Proxy-Authorization" || k == "Cookie" || k == "Set-Cookie" {
+			v = "[redacted]" // keep credentials out of logs, as RedactURL does
+		}
+		logf(LevelDebug, "%s%s: %s", prefix, k, v)
+	}
+}
@@ -0,0 +1,75 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-secret"})
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	defer SetLogger(nil, 0)
+
+	tests := []struct {
+		level   Level
+		want    []string
+		notWant []string
+	}{
+		{LevelInfo,
+			[]string{"GET " + srv.URL + "/old", "redirect 302 " + srv.URL + "/old -> " + srv.URL + "/new", "200 OK " + srv.URL + "/new in "},
+			[]string{"User-Agent", "Set-Cookie"}},
+		{LevelDebug,
+			[]string{"> User-Agent: " + DefaultUserAgent, "> Authorization: [redacted]", "< Set-Cookie: [redacted]"},
+			[]string{"hunter2", "cookie-secret"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		SetLogger(log.New(&buf, "", 0), tt.level)
+		if _, err := FetchWithBasicAuth(srv.URL+"/old", "bob", "hunter2"); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("level %d: log %q does not contain %q", tt.level, buf.String(), s)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(buf.String(), s) {
+				t.Errorf("level %d: log %q contains %q", tt.level, buf.String(), s)
+			}
+		}
+	}
+}
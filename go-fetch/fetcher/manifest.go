@@ -0,0 +1,114 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A ManifestEntry describes one URL fetched by FetchToMirrorWith.
+type ManifestEntry struct {
+	URL    string    `json:"url"`
+	Path   string    `json:"path,omitempty"`   // file the body was saved to
+	Status int       `json:"status,omitempty"` // 0 if no response was received
+	Bytes  int64     `json:"bytes"`
+	SHA256 string    `json:"sha256,omitempty"` // of the body, before any gzip
+	Time   time.Time `json:"time"`             // when the fetch started
+	Error  string    `json:"error,omitempty"`
+}
+
+// Failed reports whether the fetch e records went wrong: it ended in an
+// error, or its status was not 2xx.
+func (e ManifestEntry) Failed() bool {
+	return e.Error != "" || e.Status < 200 || e.Status > 299
+}
+
+// ReadManifest reads the entries of the manifest file written by
+// Manifest.WriteFile, so that a later run can, say, fetch again only the
+// URLs that failed.
+func ReadManifest(name string) ([]ManifestEntry, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("manifest %s: %v", name, err)
+	}
+	return entries, nil
+}
+
+// A Manifest collects ManifestEntries from concurrent fetches.
+// The zero value is an empty manifest ready to use.
+type Manifest struct {
+	mu      sync.Mutex
+	entries []ManifestEntry
+}
+
+func (m *Manifest) add(e ManifestEntry) {
+	m.mu.Lock()
+	m.entries = append(m.entries, e)
+	m.mu.Unlock()
+}
+
+// Merge adds to m those of entries whose URLs it has no entry for, as when
+// the entries of the URLs fetched again are to replace theirs in an earlier
+// manifest while the rest stay.
+func (m *Manifest) Merge(entries []ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	have := make(map[string]bool, len(m.entries))
+	for _, e := range m.entries {
+		have[e.URL] = true
+	}
+	for _, e := range entries {
+		if !have[e.URL] {
+			m.entries = append(m.entries, e)
+		}
+	}
+}
+
+// Entries returns a copy of the entries in m, sorted by URL.
+func (m *Manifest) Entries() []ManifestEntry {
+	m.mu.Lock()
+	entries := append([]ManifestEntry(nil), m.entries...)
+	m.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+	return entries
+}
+
+// WriteFile writes the entries in m to the named file as a JSON array.
+// The file is written to a temporary name and renamed into place, so a
+// reader never sees a partial manifest.
+func (m *Manifest) WriteFile(name string) error {
+	data, err := json.MarshalIndent(m.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(name, append(data, '\n'))
+}
@@ -0,0 +1,112 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+	root := t.TempDir()
+
+	var m Manifest
+	for _, p := range []string{"/missing", "/hello"} {
+		FetchToMirrorWith(srv.URL+p, root, MirrorOptions{Manifest: &m})
+	}
+	name := filepath.Join(root, "index.json")
+	if err := m.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []ManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("manifest has %d entries, want 2", len(entries))
+	}
+	hello, missing := entries[0], entries[1] // sorted by URL
+	if hello.URL != srv.URL+"/hello" || hello.Status != 200 || hello.Bytes != 5 || hello.Path == "" || hello.Error != "" || hello.Time.IsZero() ||
+		hello.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("entry for /hello = %+v, want it saved with its size and checksum", hello)
+	}
+	if missing.URL != srv.URL+"/missing" || missing.Status != 404 || missing.Path != "" || missing.Error == "" {
+		t.Errorf("entry for /missing = %+v, want a 404 with its error and no path", missing)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	var m Manifest
+	m.add(ManifestEntry{URL: "http://a/ok", Status: 200, Bytes: 5})
+	m.add(ManifestEntry{URL: "http://a/gone", Status: 404, Error: "fetch http://a/gone: 404 Not Found"})
+	m.add(ManifestEntry{URL: "http://a/refused", Error: "connection refused"})
+	name := filepath.Join(t.TempDir(), "index.json")
+	if err := m.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadManifest(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed []string
+	for _, e := range entries {
+		if e.Failed() {
+			failed = append(failed, e.URL)
+		}
+	}
+	if len(entries) != 3 || len(failed) != 2 || failed[0] != "http://a/gone" || failed[1] != "http://a/refused" {
+		t.Fatalf("ReadManifest = %d entries, failed %q; want 3, with gone and refused failed", len(entries), failed)
+	}
+
+	var replay Manifest
+	replay.add(ManifestEntry{URL: "http://a/gone", Status: 200, Bytes: 3})
+	replay.Merge(entries)
+	got := replay.Entries()
+	if len(got) != 3 || got[0].URL != "http://a/gone" || got[0].Failed() {
+		t.Errorf("Merge = %+v, want the replayed entry to replace the failed one and the rest kept", got)
+	}
+
+	if err := os.WriteFile(name, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(name); err == nil {
+		t.Errorf("ReadManifest of a corrupt file succeeded, want an error")
+	}
+}
@@ -0,0 +1,107 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+)
+
+// FetchMethod sends a request with the given method, such as "POST", to
+// url and returns the response as a Result. body, which may be nil, is sent
+// as the request body, and headers are set as FetchWithHeaders does; set
+// Content-Type to describe the body.
+//
+// Content-Length is set when the length of body is known: for a
+// *bytes.Buffer, *bytes.Reader or *strings.Reader, a regular *os.File, or
+// any reader with a Len method. Otherwise the body is sent chunked. If body
+// is an io.Closer it is closed, even when the request fails.
+func FetchMethod(method, url string, body io.Reader, headers map[string]string) (*Result, error) {
+	req, err := newRequest(context.Background(), method, url, body)
+	if err != nil {
+		if c, ok := body.(io.Closer); ok {
+			c.Close() // the client would have closed it, had there been a request to send
+		}
+		return nil, err
+	}
+	if req.ContentLength == 0 && body != nil { // net/http only measures the in-memory readers
+		if n, ok := bodyLength(body); ok {
+			req.ContentLength = n
+			if n == 0 {
+				req.Body = http.NoBody
+			}
+		}
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return fetchRequest(clientWithTimeout(DefaultTimeout), req)
+}
+
+// FetchMethodWithRetry is like FetchMethod but retries a failed request as
+// FetchWithRetryPolicy does, sending body afresh with each attempt. Only
+// idempotent methods are retried unless p.RetryNonIdempotent is set; see
+// RetryPolicy. Here a 4xx or 5xx response counts as a failure: if the last
+// attempt ends in one, the Result describing it is returned along with the
+// *RetryError.
+func FetchMethodWithRetry(method, url string, body []byte, headers map[string]string, p RetryPolicy) (*Result, error) {
+	var r *Result
+	_, err := p.do(method, url, func(io.Writer) error {
+		var rd io.Reader
+		if body != nil {
+			rd = bytes.NewReader(body)
+		}
+		var err error
+		if r, err = FetchMethod(method, url, rd, headers); err != nil {
+			return err
+		}
+		if r.StatusCode >= 400 {
+			return statusError(url, &http.Response{StatusCode: r.StatusCode, Header: r.Header})
+		}
+		return nil
+	})
+	return r, err
+}
+
+// bodyLength returns the number of bytes that remain to be read from body,
+// if that can be told without reading it.
+func bodyLength(body io.Reader) (int64, bool) {
+	switch b := body.(type) {
+	case interface{ Len() int }:
+		return int64(b.Len()), true
+	case *os.File:
+		fi, err := b.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return 0, false
+		}
+		off, err := b.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return fi.Size() - off, true
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoRequestServer answers with the method, Content-Length and body of
+// each request it receives.
+func echoRequestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %d %s", r.Method, r.ContentLength, b)
+	}))
+}
+
+func TestFetchMethod(t *testing.T) {
+	srv := echoRequestServer()
+	defer srv.Close()
+	name := filepath.Join(t.TempDir(), "body")
+	if err := os.WriteFile(name, []byte("from a file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streamed"))
+		pw.Close()
+	}()
+	for _, c := range []struct {
+		method string
+		body   io.Reader
+		want   string
+	}{
+		{http.MethodPost, strings.NewReader("a=1"), "POST 3 a=1"},
+		{http.MethodPut, f, "PUT 11 from a file"},
+		{http.MethodPost, pr, "POST -1 streamed"}, // length unknown: sent chunked
+		{http.MethodDelete, nil, "DELETE 0 "},
+	} {
+		r, err := FetchMethod(c.method, srv.URL, c.body, map[string]string{"Content-Type": "text/plain"})
+		if err != nil {
+			t.Errorf("FetchMethod(%s): %v", c.method, err)
+		} else if string(r.Body) != c.want {
+			t.Errorf("FetchMethod(%s) = %q, want %q", c.method, r.Body, c.want)
+		}
+	}
+}
+
+func TestFetchMethodWithRetry(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if n.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "%s %s", r.Method, b)
+	}))
+	defer srv.Close()
+	p := RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	for _, tt := range []struct {
+		method       string
+		nonIdem      bool
+		wantRequests int32
+		wantBody     string
+	}{
+		{http.MethodPut, false, 2, "PUT payload"},
+		{http.MethodPost, false, 1, ""},
+		{http.MethodPost, true, 2, "POST payload"},
+	} {
+		n.Store(0)
+		p.RetryNonIdempotent = tt.nonIdem
+		r, err := FetchMethodWithRetry(tt.method, srv.URL, []byte("payload"), nil, p)
+		if n.Load() != tt.wantRequests {
+			t.Errorf("%s (RetryNonIdempotent %v): %d requests, want %d", tt.method, tt.nonIdem, n.Load(), tt.wantRequests)
+		}
+		if tt.wantBody == "" {
+			var re *RetryError
+			if !errors.As(err, &re) || r == nil || r.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("%s: %v, %v; want the 503 Result and a *RetryError", tt.method, r, err)
+			}
+			continue
+		}
+		if err != nil || string(r.Body) != tt.wantBody {
+			t.Errorf("%s: %v; want %q, the body sent again on the retry", tt.method, err, tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// MetricsBuckets are the upper bounds, in seconds, of the buckets of the
+// latency histogram written by WriteMetrics: Prometheus's defaults.
+var MetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// WriteMetrics writes metrics describing results to w in the Prometheus
+// text exposition format:
+//
+//   - fetch_requests_total, a counter of the fetches by status class, such
+//     as "2xx", with class "error" for fetches that got no response;
+//   - fetch_duration_seconds, a histogram of the time each fetch took;
+//   - fetch_bytes_total, the body bytes read by all of them.
+//
+// The output can be served to a Prometheus server or left in a file for
+// the node exporter's textfile collector.
+func WriteMetrics(w io.Writer, results []Result) error {
+	bw := bufio.NewWriter(w)
+	classes := make(map[string]int)
+	buckets := make([]int, len(MetricsBuckets))
+	var sum float64
+	var bytes int64
+	for _, r := range results {
+		class := "error"
+		if r.StatusCode > 0 {
+			class = strconv.Itoa(r.StatusCode/100) + "xx"
+		}
+		classes[class]++
+		secs := r.Elapsed.Seconds()
+		sum += secs
+		for i, le := range MetricsBuckets {
+			if secs <= le {
+				buckets[i]++ // buckets are cumulative
+			}
+		}
+		bytes += r.Bytes
+	}
+
+	fmt.Fprintln(bw, "# HELP fetch_requests_total Fetches by HTTP status class, or error if no response was received.")
+	fmt.Fprintln(bw, "# TYPE fetch_requests_total counter")
+	names := make([]string, 0, len(classes))
+	for class := range classes {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+	for _, class := range names {
+		fmt.Fprintf(bw, "fetch_requests_total{class=%q} %d\n", class, classes[class])
+	}
+
+	fmt.Fprintln(bw, "# HELP fetch_duration_seconds Time taken by each fetch, including reading the body.")
+	fmt.Fprintln(bw, "# TYPE fetch_duration_seconds histogram")
+	for i, le := range MetricsBuckets {
+		fmt.Fprintf(bw, "fetch_duration_seconds_bucket{le=\"%s\"} %d\n", strconv.FormatFloat(le, 'g', -1, 64), buckets[i])
+	}
+	fmt.Fprintf(bw, "fetch_duration_seconds_bucket{le=\"+Inf\"} %d\n", len(results))
+	fmt.Fprintf(bw, "fetch_duration_seconds_sum %s\n", strconv.FormatFloat(sum, 'g', -1, 64))
+	fmt.Fprintf(bw, "fetch_duration_seconds_count %d\n", len(results))
+
+	fmt.Fprintln(bw, "# HELP fetch_bytes_total Body bytes read by all the fetches.")
+	fmt.Fprintln(bw, "# TYPE fetch_bytes_total counter")
+	fmt.Fprintf(bw, "fetch_bytes_total %d\n", bytes)
+	return bw.Flush()
+}
@@ -0,0 +1,63 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	results := []Result{
+		{StatusCode: 200, Bytes: 100, Elapsed: 250 * time.Millisecond},
+		{StatusCode: 204, Elapsed: 500 * time.Millisecond},
+		{StatusCode: 404, Bytes: 10, Elapsed: 2 * time.Second},
+		{Err: errors.New("refused")},
+	}
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE fetch_requests_total counter\n" +
+			`fetch_requests_total{class="2xx"} 2` + "\n" +
+			`fetch_requests_total{class="4xx"} 1` + "\n" +
+			`fetch_requests_total{class="error"} 1` + "\n",
+		`fetch_duration_seconds_bucket{le="0.1"} 1` + "\n" + // the failure took no time
+			`fetch_duration_seconds_bucket{le="0.25"} 2` + "\n",
+		`fetch_duration_seconds_bucket{le="0.5"} 3` + "\n",
+		`fetch_duration_seconds_bucket{le="1"} 3` + "\n",
+		`fetch_duration_seconds_bucket{le="2.5"} 4` + "\n",
+		`fetch_duration_seconds_bucket{le="+Inf"} 4` + "\n" +
+			"fetch_duration_seconds_sum 2.75\n" +
+			"fetch_duration_seconds_count 4\n",
+		"fetch_bytes_total 110\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteMetrics wrote:\n%s\nwant it to contain:\n%s", buf.String(), want)
+		}
+	}
+}
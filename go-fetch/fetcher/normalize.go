@@ -0,0 +1,104 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// hasScheme matches a URL that begins with a scheme and "://". A "://"
+// later on, as in example.com/?next=http://x, does not count.
+var hasScheme = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://`)
+
+// NormalizeURL checks that raw is a URL this package can fetch and returns
+// it in normal form. Surrounding spaces are removed, and http:// is added
+// if raw has no scheme, so that "example.com/a" becomes
+// "http://example.com/a". File URLs, such as file:///tmp/a.html, are
+// accepted too. The error names raw and says what is wrong with it.
+func NormalizeURL(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", fmt.Errorf("invalid URL %q: empty", raw)
+	}
+	if !hasScheme.MatchString(s) {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err // the url.Error repeats the input, which is already in our message
+		}
+		return "", fmt.Errorf("invalid URL %q: %v", RedactURL(raw), err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "file":
+		if h := u.Hostname(); h != "" && h != "localhost" {
+			return "", fmt.Errorf("invalid URL %q: file URL with remote host", RedactURL(raw))
+		}
+		if u.Path == "" {
+			return "", fmt.Errorf("invalid URL %q: missing path", raw)
+		}
+		return u.String(), nil
+	default:
+		return "", fmt.Errorf("invalid URL %q: unsupported scheme %q", RedactURL(raw), u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid URL %q: missing host", RedactURL(raw))
+	}
+	return u.String(), nil
+}
+
+// SameURLKey returns a key for raw, which must be a valid URL such as
+// NormalizeURL returns, that is equal for URLs that name the same resource
+// and so need only be fetched once. Two URLs are taken to be the same if
+// they differ only in that:
+//
+//   - the scheme or host is in a different case, as in HTTP://Example.COM;
+//   - one gives the scheme's default port, :80 for http or :443 for https;
+//   - the path of one has a trailing slash, so /docs and /docs/ match, and
+//     an empty path matches /;
+//   - one has a fragment, which is never sent to the server.
+//
+// The path and query are otherwise compared exactly, since servers may
+// treat their case and order as significant.
+func SameURLKey(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); port == "80" && u.Scheme == "http" || port == "443" && u.Scheme == "https" {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	u.Fragment, u.RawFragment = "", ""
+	return u.String(), nil
+}
@@ -0,0 +1,213 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"time"
+)
+
+// A Fetcher holds the settings shared by a series of fetches, so that they
+// need not be passed to each call. The zero value prints to os.Stdout with
+// DefaultTimeout and no retries, like FetchTo. A Fetcher must not be
+// changed while it is in use.
+type Fetcher struct {
+	Timeout     time.Duration     // time allowed for each request; zero means DefaultTimeout, or Client's own timeout
+	Retries     int               // retries after a failed attempt, as FetchWithRetry makes them
+	RetryDelay  time.Duration     // delay before the first retry, doubled for each one after it
+	Headers     map[string]string // set on every request, as FetchWithHeaders does
+	Concurrency int               // maximum fetches in flight in FetchAll; <= 0 means no limit
+	Client      *http.Client      // nil means a client sharing the package's transport
+	Output      io.Writer         // where Fetch and FetchAll copy content; nil means os.Stdout for Fetch and nowhere for FetchAll
+
+	// Jar, if not nil, holds the cookies set by responses and sends them
+	// with later requests, so that a session begun by one fetch carries on
+	// in the next. It is used in place of Client's own jar. By default
+	// there is none and every fetch is stateless.
+	Jar http.CookieJar
+}
+
+// An Option sets one of the settings of a Fetcher made by NewFetcher.
+type Option func(*Fetcher)
+
+// WithTimeout sets the time allowed for each request.
+func WithTimeout(d time.Duration) Option { return func(f *Fetcher) { f.Timeout = d } }
+
+// WithRetries makes the Fetcher retry a failed fetch up to n times, waiting
+// delay before the first retry.
+func WithRetries(n int, delay time.Duration) Option {
+	return func(f *Fetcher) { f.Retries, f.RetryDelay = n, delay }
+}
+
+// WithHeader sets a header sent with every request. It may be given more
+// than once.
+func WithHeader(key, value string) Option {
+	return func(f *Fetcher) {
+		if f.Headers == nil {
+			f.Headers = make(map[string]string)
+		}
+		f.Headers[key] = value
+	}
+}
+
+// WithConcurrency limits FetchAll to n fetches in flight at once.
+func WithConcurrency(n int) Option { return func(f *Fetcher) { f.Concurrency = n } }
+
+// WithClient sends the requests with client, such as one returned by
+// NewClient.
+func WithClient(client *http.Client) Option { return func(f *Fetcher) { f.Client = client } }
+
+// WithCookieJar makes the Fetcher keep cookies in jar.
+func WithCookieJar(jar http.CookieJar) Option { return func(f *Fetcher) { f.Jar = jar } }
+
+// WithCookies turns cookie handling on, with a new empty jar, or off. The
+// jar has no public suffix list, so it lets a host set cookies for any
+// parent domain; pass a jar made with one to WithCookieJar if that matters.
+func WithCookies(enabled bool) Option {
+	return func(f *Fetcher) {
+		f.Jar = nil
+		if enabled {
+			f.Jar, _ = cookiejar.New(nil) // New never fails without options
+		}
+	}
+}
+
+// WithOutput makes Fetch copy content to w.
+func WithOutput(w io.Writer) Option { return func(f *Fetcher) { f.Output = w } }
+
+// NewFetcher returns a Fetcher with the given options applied, in order, to
+// the zero value.
+func NewFetcher(opts ...Option) *Fetcher {
+	f := new(Fetcher)
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Fetch copies the content found at url to f.Output and returns the number
+// of bytes copied. A 4xx or 5xx response is reported as a *StatusError.
+// With retries, a failed fetch is retried as FetchWithRetryPolicy retries
+// it, and the content is buffered so that a failed attempt writes nothing.
+func (f *Fetcher) Fetch(url string) (int64, error) {
+	client := f.client()
+	w := f.Output
+	if w == nil {
+		w = os.Stdout
+	}
+	if f.Retries <= 0 {
+		return f.fetch(client, w, url)
+	}
+	p := RetryPolicy{MaxRetries: f.Retries, BaseDelay: f.RetryDelay}
+	buf, err := p.do(http.MethodGet, url, func(w io.Writer) error {
+		_, err := f.fetch(client, w, url)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// FetchAll fetches urls, at most f.Concurrency at a time, and returns one
+// Result per URL in the same order, as FetchAll does. Failed fetches are
+// retried as f.Retries and f.RetryDelay say. If f.Output is set, the body
+// of each successful fetch is then copied to it, in input order; if that
+// fails, the Result of the body that could not be written has the error,
+// and no more bodies are written. Unlike Fetch, FetchAll writes nothing if
+// f.Output is nil.
+func (f *Fetcher) FetchAll(urls []string) []Result {
+	results := FetchBatch(urls, BatchOptions{
+		Client:      f.client(),
+		Concurrency: f.Concurrency,
+		Headers:     f.Headers,
+		Retries:     f.Retries,
+		RetryDelay:  f.RetryDelay,
+		KeepBody:    f.Output != nil,
+	})
+	if f.Output == nil {
+		return results
+	}
+	for i := range results {
+		r := &results[i]
+		if r.Failed() {
+			continue
+		}
+		if _, err := f.Output.Write(r.Body); err != nil {
+			r.Err = fmt.Errorf("writing the body of %s: %w", RedactURL(r.URL), err)
+			break
+		}
+	}
+	return results
+}
+
+// client returns the client f's requests are sent with.
+func (f *Fetcher) client() *http.Client {
+	if f.Client == nil && f.Jar == nil {
+		timeout := f.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		return clientWithTimeout(timeout)
+	}
+	if f.Timeout == 0 && f.Jar == nil {
+		return f.Client
+	}
+	c := http.Client{Transport: transport(), Timeout: DefaultTimeout}
+	if f.Client != nil {
+		c = *f.Client // keep the caller's client as it was
+	}
+	if f.Timeout != 0 {
+		c.Timeout = f.Timeout
+	}
+	if f.Jar != nil {
+		c.Jar = f.Jar
+	}
+	return &c
+}
+
+// fetch makes one attempt to copy the content found at url to w.
+func (f *Fetcher) fetch(client *http.Client, w io.Writer, url string) (int64, error) {
+	ctx := context.Background()
+	req, err := newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range f.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := send(client, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, statusError(url, resp)
+	}
+	return copyBody(ctx, w, resp.Body, url, client.Timeout)
+}
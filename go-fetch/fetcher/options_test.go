@@ -0,0 +1,119 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer answers the first fails requests for each path with 503 and
+// the rest with the path, counting the requests for each path.
+func flakyServer(fails int) (*httptest.Server, map[string]int, *sync.Mutex) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.URL.Path]++
+		n := counts[r.URL.Path]
+		mu.Unlock()
+		if r.Header.Get("X-Token") != "t" {
+			http.Error(w, "no token", http.StatusUnauthorized)
+			return
+		}
+		if n <= fails {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	return srv, counts, &mu
+}
+
+func TestFetcherFetch(t *testing.T) {
+	srv, counts, mu := flakyServer(1)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	f := NewFetcher(WithOutput(&out), WithHeader("X-Token", "t"), WithRetries(1, time.Millisecond))
+	if n, err := f.Fetch(srv.URL + "/a"); err != nil || n != 2 || out.String() != "/a" {
+		t.Errorf("Fetch = %d, %v, wrote %q; want /a after a retry", n, err, out.String())
+	}
+	mu.Lock()
+	if counts["/a"] != 2 {
+		t.Errorf("%d requests, want 2", counts["/a"])
+	}
+	mu.Unlock()
+
+	out.Reset()
+	var se *StatusError
+	f = NewFetcher(WithOutput(&out), WithTimeout(time.Second))
+	if _, err := f.Fetch(srv.URL + "/b"); !errors.As(err, &se) || se.StatusCode != http.StatusUnauthorized || out.Len() != 0 {
+		t.Errorf("Fetch without the header = %v, wrote %q; want a 401 *StatusError and nothing written", err, out.String())
+	}
+}
+
+func TestFetcherFetchAllRetries(t *testing.T) {
+	srv, counts, mu := flakyServer(2)
+	defer srv.Close()
+	var out bytes.Buffer
+	f := NewFetcher(WithRetries(2, time.Millisecond), WithHeader("X-Token", "t"), WithConcurrency(2), WithOutput(&out))
+	results := f.FetchAll([]string{srv.URL + "/a", srv.URL + "/b"})
+	for _, r := range results {
+		if r.Failed() {
+			t.Errorf("%s: status %d, err %v; want success after two retries", r.URL, r.StatusCode, r.Err)
+		}
+	}
+	mu.Lock()
+	if counts["/a"] != 3 || counts["/b"] != 3 {
+		t.Errorf("requests = %v, want 3 to each path", counts)
+	}
+	mu.Unlock()
+	if out.String() != "/a/b" {
+		t.Errorf("Output got %q, want the bodies in input order", out.String())
+	}
+}
+
+func TestFetcherFetchAllRetriesExhausted(t *testing.T) {
+	srv, counts, mu := flakyServer(5)
+	defer srv.Close()
+	var out bytes.Buffer
+	f := NewFetcher(WithRetries(1, time.Millisecond), WithHeader("X-Token", "t"), WithOutput(&out))
+	r := f.FetchAll([]string{srv.URL + "/c"})[0]
+	if r.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503 once the retries are used up", r.StatusCode)
+	}
+	mu.Lock()
+	if counts["/c"] != 2 {
+		t.Errorf("%d requests, want 2", counts["/c"])
+	}
+	mu.Unlock()
+	if out.Len() != 0 {
+		t.Errorf("Output got %q from a failed fetch", out.String())
+	}
+}
@@ -0,0 +1,69 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WaitUntilOK fetches url with client, nil meaning the package's shared
+// client, again and again, interval apart, until the server answers with a
+// 2xx status or ctx is done. It is for waiting on a service that is
+// starting up, such as polling a health check. It returns the last
+// Result and the number of fetches made, with a nil error on success and
+// otherwise ctx.Err(); a fetch cut short by ctx is not counted unless it
+// was the only one. Each failed attempt is logged at LevelInfo.
+func WaitUntilOK(ctx context.Context, client *http.Client, url string, interval time.Duration) (Result, int, error) {
+	if client == nil {
+		client = clientWithTimeout(DefaultTimeout)
+	}
+	opts := BatchOptions{Client: client}
+	var last Result
+	for attempts := 1; ; attempts++ {
+		r := fetchResult(ctx, url, &opts)
+		if r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 300 {
+			return r, attempts, nil
+		}
+		if ctx.Err() != nil {
+			if attempts > 1 {
+				return last, attempts - 1, ctx.Err() // the attempt cut short says nothing about the server
+			}
+			return r, attempts, ctx.Err()
+		}
+		last = r
+		if r.Err != nil {
+			logf(LevelInfo, "waiting for %s: attempt %d: %v", RedactURL(url), attempts, r.Err)
+		} else {
+			logf(LevelInfo, "waiting for %s: attempt %d: %d %s", RedactURL(url), attempts, r.StatusCode, http.StatusText(r.StatusCode))
+		}
+		t := time.NewTimer(interval)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return r, attempts, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitUntilOK(t *testing.T) {
+	var n atomic.Int32
+	srv := failingServer(http.StatusServiceUnavailable, 2, &n)
+	defer srv.Close()
+
+	r, attempts, err := WaitUntilOK(context.Background(), nil, srv.URL, time.Millisecond)
+	if err != nil || attempts != 3 || r.StatusCode != http.StatusOK {
+		t.Errorf("WaitUntilOK = %d after %d attempts, %v; want 200 after 3", r.StatusCode, attempts, err)
+	}
+
+	n.Store(0)
+	down := failingServer(http.StatusServiceUnavailable, 1000, &n)
+	defer down.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r, attempts, err = WaitUntilOK(ctx, nil, down.URL, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || attempts < 2 || r.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("WaitUntilOK of a server that stays down = %d after %d attempts, %v; want the last 503 and DeadlineExceeded", r.StatusCode, attempts, err)
+	}
+}
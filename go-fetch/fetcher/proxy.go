@@ -0,0 +1,66 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// FetchWithProxy is like FetchResponse but sends the request through the
+// proxy at proxyURL, such as "http://proxy.example.com:3128" or
+// "socks5://127.0.0.1:1080", whatever the environment says. The other fetch
+// functions use the proxy named by HTTP_PROXY and HTTPS_PROXY, if any.
+func FetchWithProxy(rawURL, proxyURL string) (*Result, error) {
+	proxy, err := ParseProxy(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(ClientOptions{Proxy: proxy})
+	defer client.CloseIdleConnections() // the client is not reused, so neither are its connections
+	return fetchResponse(context.Background(), client, rawURL)
+}
+
+// ParseProxy parses a proxy URL for ClientOptions.Proxy, checking that its
+// scheme is one net/http can use.
+func ParseProxy(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err // the url.Error repeats the input, password and all
+		}
+		return nil, fmt.Errorf("invalid proxy URL %q: %v", RedactURL(proxyURL), err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", RedactURL(proxyURL), u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", RedactURL(proxyURL))
+	}
+	return u, nil
+}
@@ -0,0 +1,82 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// A Limiter spaces requests out evenly so that no more than a fixed number
+// start per second, however many goroutines are fetching. Callers block in
+// Wait until their turn comes rather than failing. A Limiter is safe for
+// concurrent use; share one across all the fetches to be throttled.
+type Limiter struct {
+	lim *rate.Limiter // with a burst of 1, so that no two requests start together
+}
+
+// NewLimiter returns a Limiter allowing perSecond requests per second. If
+// perSecond is zero or negative it returns nil, which never waits.
+func NewLimiter(perSecond float64) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &Limiter{lim: rate.NewLimiter(rate.Limit(perSecond), 1)}
+}
+
+// Wait blocks until the caller may start a request, or until ctx is done,
+// in which case it returns ctx.Err(). If the turn would come only after
+// ctx's deadline, it returns context.DeadlineExceeded at once. A nil
+// *Limiter never waits.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if err := l.lim.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return context.DeadlineExceeded // rather than rate's own error, so that callers see the deadline
+	}
+	return nil
+}
+
+// jitter waits for a random time from zero up to max, or until ctx is
+// done, in which case it returns ctx.Err(). It spreads out requests that
+// would otherwise start together.
+func jitter(ctx context.Context, max time.Duration) error {
+	if max <= 0 {
+		return nil
+	}
+	t := time.NewTimer(time.Duration(rand.Int63n(int64(max) + 1)))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterWait(t *testing.T) {
+	const perSecond, n = 20, 10
+	l := NewLimiter(perSecond)
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	first, last := starts[0], starts[0]
+	for _, s := range starts {
+		if s.Before(first) {
+			first = s
+		}
+		if s.After(last) {
+			last = s
+		}
+	}
+	// n starts at perSecond need (n-1)/perSecond seconds, 450ms; allow for
+	// timer slack.
+	if want := 400 * time.Millisecond; last.Sub(first) < want {
+		t.Errorf("%d concurrent waits spanned %v, want at least %v", n, last.Sub(first), want)
+	}
+}
+
+func TestLimiterWaitContext(t *testing.T) {
+	if err := (*Limiter)(nil).Wait(context.Background()); err != nil {
+		t.Errorf("nil Limiter: Wait = %v, want nil", err)
+	}
+	if NewLimiter(0) != nil {
+		t.Error("NewLimiter(0) is not nil")
+	}
+
+	l := NewLimiter(1)
+	l.Wait(context.Background()) // take the first turn
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("turn after the deadline: Wait = %v, want context.DeadlineExceeded", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled context: Wait = %v, want context.Canceled", err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		if err := jitter(ctx, 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("jitter(10ms) waited %v", d)
+		}
+	}
+	if err := jitter(ctx, 0); err != nil {
+		t.Errorf("jitter(0) = %v, want nil", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := jitter(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("jitter(1h) with a cancelled context = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrTooManyRedirects is returned, wrapped, by FetchWithRedirects when a
+// URL redirects more times than allowed.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// FetchWithRedirects fetches url like FetchResponse, following at most
+// maxRedirects redirects. The Result records each URL redirected to and the
+// URL finally reached. If the limit is exceeded, the error wraps
+// ErrTooManyRedirects and the Result still holds the redirects followed.
+func FetchWithRedirects(url string, maxRedirects int) (*Result, error) {
+	var chain []string
+	client := &http.Client{
+		Transport: transport(),
+		Timeout:   DefaultTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+			}
+			chain = append(chain, req.URL.String())
+			return nil
+		},
+	}
+	var hops []Hop
+	r, err := fetchResponse(context.Background(), withHops(client, &hops), url)
+	if r == nil {
+		r = &Result{URL: url, Err: err}
+	}
+	r.Redirects, r.Hops = chain, hops
+	return r, err
+}
+
+// A Hop is one redirect in the chain that led to a response: the URL that
+// was requested, the 3xx status it answered with, and the time from sending
+// the request to receiving that answer.
+type Hop struct {
+	URL        string
+	StatusCode int
+	Elapsed    time.Duration
+}
+
+// withHops returns a copy of client that appends to *hops each redirect it
+// follows, as its CheckRedirect, or net/http's default of at most 10
+// redirects, allows. The time of the first hop counts from now.
+func withHops(client *http.Client, hops *[]Hop) *http.Client {
+	c := *client
+	check := c.CheckRedirect
+	start := time.Now()
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		var err error
+		switch {
+		case check != nil:
+			err = check(req, via)
+		case len(via) >= 10:
+			err = errors.New("stopped after 10 redirects") // as net/http's own policy says
+		}
+		if err == nil {
+			now := time.Now()
+			*hops = append(*hops, Hop{URL: via[len(via)-1].URL.String(), StatusCode: req.Response.StatusCode, Elapsed: now.Sub(start)})
+			start = now
+		}
+		return err
+	}
+	return &c
+}
@@ -0,0 +1,103 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// redirectServer redirects /r/N to /r/N-1, and answers /r/0 with 200 OK.
+func redirectServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/r/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/r/%d", n-1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("arrived"))
+	}))
+}
+
+func TestFetchWithRedirects(t *testing.T) {
+	srv := redirectServer()
+	defer srv.Close()
+
+	r, err := FetchWithRedirects(srv.URL+"/r/3", 5)
+	if err != nil || string(r.Body) != "arrived" || r.FinalURL != srv.URL+"/r/0" {
+		t.Fatalf("FetchWithRedirects(/r/3, 5) = %v, %v; want to arrive at /r/0", r, err)
+	}
+	if want := []string{srv.URL + "/r/2", srv.URL + "/r/1", srv.URL + "/r/0"}; strings.Join(r.Redirects, " ") != strings.Join(want, " ") {
+		t.Errorf("Redirects = %q, want %q", r.Redirects, want)
+	}
+
+	r, err = FetchWithRedirects(srv.URL+"/r/3", 2)
+	if !errors.Is(err, ErrTooManyRedirects) || len(r.Redirects) != 2 {
+		t.Errorf("FetchWithRedirects(/r/3, 2) = %d redirects, %v; want 2 and ErrTooManyRedirects", len(r.Redirects), err)
+	}
+}
+
+func TestNewClientNoRedirect(t *testing.T) {
+	srv := redirectServer()
+	defer srv.Close()
+
+	r, err := FetchResponseWithClient(NewClient(ClientOptions{NoRedirect: true}), srv.URL+"/r/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StatusCode != http.StatusFound || r.Header.Get("Location") != "/r/1" || r.FinalURL != srv.URL+"/r/2" {
+		t.Errorf("NoRedirect: %d to %s, Location %q; want the 302 itself, pointing to /r/1", r.StatusCode, r.FinalURL, r.Header.Get("Location"))
+	}
+	if r, err := FetchResponseWithClient(NewClient(ClientOptions{}), srv.URL+"/r/2"); err != nil || r.StatusCode != http.StatusOK {
+		t.Errorf("without NoRedirect: %v, %v; want the redirects followed", r, err)
+	}
+}
+
+func TestFetchBatchHops(t *testing.T) {
+	srv := redirectServer()
+	defer srv.Close()
+
+	r := FetchBatch([]string{srv.URL + "/r/2"}, BatchOptions{})[0]
+	if r.Err != nil || r.FinalURL != srv.URL+"/r/0" || len(r.Hops) != 2 {
+		t.Fatalf("FetchBatch(/r/2) = %s, %v, %d hops; want /r/0 after 2", r.FinalURL, r.Err, len(r.Hops))
+	}
+	for i, from := range []string{"/r/2", "/r/1"} {
+		if h := r.Hops[i]; h.URL != srv.URL+from || h.StatusCode != http.StatusFound || h.Elapsed <= 0 {
+			t.Errorf("hop %d = %+v, want a 302 from %s with its time", i, h, from)
+		}
+	}
+
+	client := NewClient(ClientOptions{NoRedirect: true})
+	if r := FetchBatch([]string{srv.URL + "/r/2"}, BatchOptions{Client: client})[0]; r.StatusCode != http.StatusFound || len(r.Hops) != 0 {
+		t.Errorf("FetchBatch with NoRedirect = %d, %d hops; want the 302 and none", r.StatusCode, len(r.Hops))
+	}
+	r = FetchBatch([]string{srv.URL + "/r/12"}, BatchOptions{})[0]
+	if r.Err == nil || len(r.Hops) != 9 { // the tenth redirect is refused, not followed
+		t.Errorf("FetchBatch(/r/12) = %v, %d hops; want to stop after 9", r.Err, len(r.Hops))
+	}
+}
@@ -0,0 +1,83 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// dialRetryDelay is the pause between the connection attempts made by a
+// client whose ClientOptions.DialAttempts is more than one.
+const dialRetryDelay = 250 * time.Millisecond
+
+// newResolver returns a resolver that sends its queries to server over
+// network, "udp" if empty, rather than to the servers the system would use.
+func newResolver(server, network string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(trimBrackets(server), "53") // no port given
+	}
+	if network == "" {
+		network = "udp"
+	}
+	d := &net.Dialer{Timeout: 5 * time.Second}
+	return &net.Resolver{
+		PreferGo: true, // the cgo resolver would ignore Dial
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// trimBrackets removes the brackets around an IPv6 address such as [::1].
+func trimBrackets(host string) string {
+	if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+		return host[1 : len(host)-1]
+	}
+	return host
+}
+
+// retryDial returns a dial function that calls dial up to attempts times,
+// pausing between them, until a connection is made or ctx is done.
+func retryDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), attempts int) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var err error
+		for i := 1; i <= attempts; i++ {
+			var conn net.Conn
+			if conn, err = dial(ctx, network, addr); err == nil {
+				return conn, nil
+			}
+			if i == attempts || ctx.Err() != nil {
+				break
+			}
+			logf(LevelInfo, "dial %s: attempt %d failed: %v", addr, i, err)
+			select {
+			case <-time.After(dialRetryDelay):
+			case <-ctx.Done():
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+}
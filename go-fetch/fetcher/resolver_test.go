@@ -0,0 +1,100 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDNSServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	queried := make(chan bool, 1)
+	go func() {
+		conn, err := l.Accept()
+		queried <- err == nil
+		if err == nil {
+			conn.Close() // no answer: the lookup fails
+		}
+	}()
+
+	client := NewClient(ClientOptions{DNSServer: l.Addr().String(), DNSNetwork: "tcp"})
+	if _, err := FetchResponseWithClient(client, "http://no-such-host.example/"); err == nil {
+		t.Error("fetched through a DNS server that gives no answers")
+	}
+	select {
+	case ok := <-queried:
+		if !ok {
+			t.Errorf("accepting the lookup at %s failed", l.Addr())
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("the lookup was not sent to %s", l.Addr())
+	}
+}
+
+func TestTrimBrackets(t *testing.T) {
+	for in, want := range map[string]string{"[::1]": "::1", "8.8.8.8": "8.8.8.8", "[": "[", "": ""} {
+		if got := trimBrackets(in); got != want {
+			t.Errorf("trimBrackets(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRetryDial(t *testing.T) {
+	refused := errors.New("connection refused")
+	calls := 0
+	dial := func(context.Context, string, string) (net.Conn, error) {
+		calls++
+		if calls < 3 {
+			return nil, refused
+		}
+		c, _ := net.Pipe()
+		return c, nil
+	}
+	ctx := context.Background()
+
+	conn, err := retryDial(dial, 3)(ctx, "tcp", "a:80")
+	if err != nil || calls != 3 {
+		t.Fatalf("retryDial with 3 attempts = %v after %d calls; want a connection on the third", err, calls)
+	}
+	conn.Close()
+
+	calls = 0
+	if _, err := retryDial(dial, 2)(ctx, "tcp", "a:80"); err != refused || calls != 2 {
+		t.Errorf("retryDial with 2 attempts = %v after %d calls; want the last error after 2", err, calls)
+	}
+
+	calls = 0
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := retryDial(dial, 3)(ctx, "tcp", "a:80"); err == nil || calls != 1 {
+		t.Errorf("retryDial with a cancelled context = %v after %d calls; want to give up after 1", err, calls)
+	}
+}
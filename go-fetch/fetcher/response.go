@@ -0,0 +1,91 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+)
+
+// maxPrealloc is the most FetchResponse allocates up front for a body on
+// the strength of its Content-Length.
+const maxPrealloc = 1 << 20
+
+// FetchResponse fetches url and returns its status code, header and body.
+// The body is read once, straight into the Result. If reading the body
+// fails, the Result holds what was read before the error.
+func FetchResponse(url string) (*Result, error) {
+	return fetchResponse(context.Background(), clientWithTimeout(DefaultTimeout), url)
+}
+
+// FetchResponseWithClient is like FetchResponse but sends the request with
+// client, such as one returned by NewClient.
+func FetchResponseWithClient(client *http.Client, url string) (*Result, error) {
+	return fetchResponse(context.Background(), client, url)
+}
+
+// fetchResponse is FetchResponse using client.
+func fetchResponse(ctx context.Context, client *http.Client, url string) (*Result, error) {
+	req, err := newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetchRequest(client, req)
+}
+
+// fetchRequest sends req with client and reads the response into a Result.
+func fetchRequest(client *http.Client, req *http.Request) (*Result, error) {
+	start := time.Now()
+	url := req.URL.String()
+	req, tr := traceRequest(req)
+	resp, err := send(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	if n := resp.ContentLength; n > 0 {
+		if n > maxPrealloc {
+			n = maxPrealloc // the declared length may be wrong; let a body that big grow as it arrives
+		}
+		buf.Grow(int(n)) // avoid regrowing the buffer when the size is known
+	}
+	n, err := copyBody(req.Context(), &buf, resp.Body, url, client.Timeout)
+	r := &Result{
+		URL:           url,
+		FinalURL:      resp.Request.URL.String(),
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		Header:        resp.Header,
+		ContentLength: resp.ContentLength,
+		Body:          buf.Bytes(),
+		Bytes:         n,
+		WireBytes:     wireBytes(resp),
+		Err:           err,
+	}
+	r.setTimes(start)
+	r.Timing = tr.done(r.Elapsed)
+	return r, err
+}
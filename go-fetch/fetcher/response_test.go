@@ -0,0 +1,107 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "yes")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("made"))
+	}))
+	defer srv.Close()
+	r, err := FetchResponse(srv.URL + "/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StatusCode != http.StatusCreated || r.Header.Get("X-Test") != "yes" || string(r.Body) != "made" {
+		t.Errorf("FetchResponse = %d, X-Test %q, body %q; want 201, yes, made", r.StatusCode, r.Header.Get("X-Test"), r.Body)
+	}
+	if r.ContentLength != 4 || r.Bytes != 4 || r.URL != srv.URL+"/new" || r.FinalURL != r.URL {
+		t.Errorf("FetchResponse = %+v, want 4 bytes from %s/new", r, srv.URL)
+	}
+}
+
+// chunkedServer sends the chunks of its body one at a time, flushing after
+// each, with no Content-Length, gzip-compressed if the path is /gzip.
+func chunkedServer(chunks []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out io.Writer = w
+		var zw *gzip.Writer
+		if r.URL.Path == "/gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw = gzip.NewWriter(w)
+			out = zw
+		}
+		for _, c := range chunks {
+			out.Write([]byte(c))
+			if zw != nil {
+				zw.Flush()
+			}
+			w.(http.Flusher).Flush() // ends this chunk, so no Content-Length is sent
+		}
+		if zw != nil {
+			zw.Close()
+		}
+	}))
+}
+
+func TestFetchResponseChunked(t *testing.T) {
+	chunks := []string{"first chunk\n", strings.Repeat("x", 64<<10), "\nlast chunk\n"}
+	want := strings.Join(chunks, "")
+	srv := chunkedServer(chunks)
+	defer srv.Close()
+	for _, path := range []string{"/", "/gzip"} {
+		r, err := FetchResponse(srv.URL + path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if r.ContentLength != -1 {
+			t.Errorf("%s: ContentLength = %d, want -1 for a chunked response", path, r.ContentLength)
+		}
+		if string(r.Body) != want || r.Bytes != int64(len(want)) {
+			t.Errorf("%s: read %d bytes (Bytes %d), want all %d", path, len(r.Body), r.Bytes, len(want))
+		}
+	}
+}
+
+func TestFetchToChunked(t *testing.T) {
+	chunks := []string{"a", "bb", "ccc", strings.Repeat("d", 10000)}
+	want := strings.Join(chunks, "")
+	srv := chunkedServer(chunks)
+	defer srv.Close()
+	var buf bytes.Buffer
+	n, err := FetchTo(&buf, srv.URL+"/gzip")
+	if err != nil || n != int64(len(want)) || buf.String() != want {
+		t.Errorf("FetchTo = %d, %v; got %d bytes, want %d", n, err, buf.Len(), len(want))
+	}
+}
@@ -0,0 +1,157 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// A Result describes the outcome of fetching a single URL.
+type Result struct {
+	URL           string        // the URL that was fetched
+	Label         string        // the label given to the URL by BatchOptions.Labels, if any
+	FinalURL      string        // the URL of the final response, after any redirects
+	Redirects     []string      // the URLs redirected to, in order; only set by FetchWithRedirects
+	Hops          []Hop         // the redirects followed, in order, with their statuses and times; set by batches and FetchWithRedirects
+	StatusCode    int           // HTTP status code, if a response was received
+	Proto         string        // protocol of the response, such as "HTTP/1.1" or "HTTP/2.0"
+	Header        http.Header   // response header, if a response was received
+	ContentLength int64         // length declared by the server; -1 if unknown or the body was compressed
+	NotModified   bool          // the server answered 304 to FetchIfChanged: the content is unchanged
+	Body          []byte        // response body; only set by the functions returning a *Result and by batches with KeepBody
+	Bytes         int64         // number of body bytes actually read, after decompression
+	WireBytes     int64         // number of body bytes received, before decompression
+	SHA256        string        // hex SHA-256 of the body; only set when a checksum is asked for
+	Start         time.Time     // when the fetch began
+	End           time.Time     // when the fetch ended, with the body read or an error
+	Elapsed       time.Duration // End minus Start: the time taken by the whole fetch
+	Timing        Timing        // breakdown of Elapsed into phases
+	Err           error         // non-nil if the fetch failed
+}
+
+// Failed reports whether the fetch went wrong: it returned an error, or the
+// server answered with a 4xx or 5xx status.
+func (r Result) Failed() bool {
+	return r.Err != nil || r.StatusCode >= 400
+}
+
+// Slow reports whether the fetch succeeded but took longer than threshold.
+// A zero or negative threshold means no fetch is slow.
+func (r Result) Slow(threshold time.Duration) bool {
+	return threshold > 0 && !r.Failed() && r.Elapsed > threshold
+}
+
+// err returns the error that made the fetch fail: r.Err, wrapped to name
+// r.URL if it does not already, or a *StatusError for a 4xx or 5xx
+// response. It returns nil if r did not fail.
+func (r Result) err() error {
+	if r.Err != nil {
+		if namesURL(r.Err) {
+			return r.Err
+		}
+		return fmt.Errorf("fetch %s: %w", RedactURL(r.URL), r.Err)
+	}
+	if r.StatusCode >= 400 {
+		return &StatusError{URL: r.URL, StatusCode: r.StatusCode}
+	}
+	return nil
+}
+
+// fetchResult fetches url with opts.Client, which must not be nil, and
+// describes the outcome. The body is discarded once it has been measured,
+// hashed if opts.Checksum is set and checked by opts.Check, if any, unless
+// opts.KeepBody is set.
+func fetchResult(ctx context.Context, url string, opts *BatchOptions) Result {
+	var hops []Hop
+	client := withHops(opts.Client, &hops)
+	start := time.Now()
+	r := Result{URL: url}
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := newRequest(ctx, method, url, nil)
+	if err != nil {
+		r.Err = err
+		r.setTimes(start)
+		return r
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+	req, tr := traceRequest(req)
+	resp, err := send(client, req)
+	if err == nil {
+		r.FinalURL = resp.Request.URL.String()
+		r.StatusCode, r.Header, r.ContentLength, r.Proto = resp.StatusCode, resp.Header, resp.ContentLength, resp.Proto
+		dst := io.Discard
+		var body bytes.Buffer
+		if opts.KeepBody || opts.Check != nil {
+			dst = &body
+		}
+		h := sha256.New()
+		if opts.Checksum {
+			dst = io.MultiWriter(dst, h) // hash the body as it streams past
+		}
+		r.Bytes, err = copyBody(ctx, dst, resp.Body, url, client.Timeout)
+		r.WireBytes = wireBytes(resp)
+		if opts.Checksum && err == nil {
+			r.SHA256 = hex.EncodeToString(h.Sum(nil))
+		}
+		if opts.Check != nil && err == nil && resp.StatusCode < 400 {
+			if cerr := opts.Check(body.Bytes()); cerr != nil {
+				err = &CheckError{URL: url, Err: cerr}
+			}
+		}
+		if opts.KeepBody {
+			r.Body = body.Bytes()
+		}
+		resp.Body.Close()
+	}
+	r.Err, r.Hops = err, hops
+	r.setTimes(start)
+	r.Timing = tr.done(r.Elapsed)
+	return r
+}
+
+// setTimes records that the fetch described by r began at start and has
+// just ended. Every function that builds a Result uses it, so that all
+// fetches are timed the same way.
+func (r *Result) setTimes(start time.Time) {
+	r.Start, r.End = start, time.Now()
+	r.Elapsed = r.End.Sub(start)
+}
+
+// FetchConcurrentResult is like FetchConcurrent but sends a Result rather
+// than a formatted line on ch, so the caller can sort, filter and total the
+// results without parsing them. It sends exactly one Result.
+func FetchConcurrentResult(url string, ch chan<- Result) {
+	ch <- fetchResult(context.Background(), url, &BatchOptions{Client: clientWithTimeout(DefaultTimeout)})
+}
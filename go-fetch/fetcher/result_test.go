@@ -0,0 +1,83 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchConcurrentResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+	ch := make(chan Result, 2)
+	go FetchConcurrentResult(srv.URL+"/ok", ch)
+	go FetchConcurrentResult(srv.URL+"/missing", ch)
+	got := map[string]Result{}
+	for i := 0; i < 2; i++ {
+		r := <-ch
+		got[r.URL] = r
+	}
+	if r := got[srv.URL+"/ok"]; r.Err != nil || r.StatusCode != http.StatusOK || r.Bytes != 5 || r.Failed() || r.Elapsed <= 0 {
+		t.Errorf("ok: %+v, want 5 bytes with 200 OK", r)
+	}
+	if r := got[srv.URL+"/missing"]; r.StatusCode != http.StatusNotFound || !r.Failed() {
+		t.Errorf("missing: %+v, want a failed 404", r)
+	}
+}
+
+func TestResultFailed(t *testing.T) {
+	for _, c := range []struct {
+		r    Result
+		want bool
+	}{
+		{Result{StatusCode: 200}, false},
+		{Result{StatusCode: 304}, false},
+		{Result{StatusCode: 404}, true},
+		{Result{StatusCode: 503}, true},
+		{Result{Err: errors.New("refused")}, true},
+	} {
+		if got := c.r.Failed(); got != c.want {
+			t.Errorf("Result{StatusCode: %d, Err: %v}.Failed() = %t, want %t", c.r.StatusCode, c.r.Err, got, c.want)
+		}
+	}
+}
+
+func TestResultSlow(t *testing.T) {
+	r := Result{StatusCode: http.StatusOK, Elapsed: 2 * time.Second}
+	if !r.Slow(time.Second) || r.Slow(3*time.Second) || r.Slow(0) {
+		t.Errorf("a 2s fetch: Slow(1s), Slow(3s), Slow(0) = %t, %t, %t; want true, false, false", r.Slow(time.Second), r.Slow(3*time.Second), r.Slow(0))
+	}
+	if r.StatusCode = http.StatusInternalServerError; r.Slow(time.Second) {
+		t.Error("a failed fetch is Slow")
+	}
+}
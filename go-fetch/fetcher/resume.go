@@ -0,0 +1,169 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FetchResumable saves the content found at url in destFile, continuing a
+// partial download left by an earlier call instead of starting over. If
+// destFile exists, only the bytes after its current size are asked for,
+// with a Range header, and appended. If the server does not answer with
+// 206 Partial Content for exactly that range, the file is truncated and
+// fetched again in full. It returns the size of the complete file.
+//
+// A partial file is kept when the fetch fails, ready for the next call. The
+// server's copy must not have changed in the meantime: nothing checks that
+// the two halves belong together.
+//
+// However large the file, the download may take as long as it needs while
+// data keeps arriving: it fails with a *TimeoutError only when
+// DefaultTimeout passes with nothing received.
+func FetchResumable(url, destFile string) (int64, error) {
+	return FetchResumableWithClient(clientWithTimeout(DefaultTimeout), url, destFile)
+}
+
+// FetchResumableWithClient is like FetchResumable but sends the requests
+// with client. The client's Timeout bounds not the whole download but each
+// wait for data: for the response, and between reads of the body. Zero
+// means no limit.
+func FetchResumableWithClient(client *http.Client, url, destFile string) (int64, error) {
+	f, err := os.OpenFile(destFile, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	c := *client
+	c.Timeout = 0 // fetchRange's idle timer takes its place
+	offset := fi.Size()
+	n, err := fetchRange(&c, client.Timeout, f, url, offset)
+	if err == errNoRange {
+		offset = 0
+		n, err = fetchRange(&c, client.Timeout, f, url, 0)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return offset + n, err
+}
+
+// errNoRange is returned by fetchRange when the server will not send the
+// range asked for.
+var errNoRange = errors.New("server does not support the range")
+
+// fetchRange writes the content found at url, from offset on, to f at
+// offset, truncating f there first, with client, giving up when idle passes
+// without data arriving. For offset zero it fetches the whole content;
+// otherwise it returns errNoRange, having written nothing, unless the
+// server sends the range. A file already complete is reported as zero
+// bytes written.
+func fetchRange(client *http.Client, idle time.Duration, f *os.File, url string, offset int64) (int64, error) {
+	timer := newIdleTimer(context.Background(), idle)
+	defer timer.stop()
+	ctx := timer.ctx
+	req, err := newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept-Encoding", "identity") // a range of compressed bytes could not be decompressed on its own
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := send(client, req)
+	if err != nil {
+		return 0, timer.wrap(url, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case offset == 0 && resp.StatusCode == http.StatusOK:
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		if start, _, ok := contentRange(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return 0, errNoRange
+		}
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		if _, size, ok := contentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			return 0, nil // the earlier download got everything
+		}
+		return 0, errNoRange
+	case offset > 0 && resp.StatusCode == http.StatusOK:
+		return 0, errNoRange // the server ignored the Range header
+	default:
+		return 0, statusError(url, resp)
+	}
+	if err := f.Truncate(offset); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := copyBody(ctx, f, timer.reader(resp.Body), url, idle)
+	if err != nil && timer.expired() {
+		err = timer.wrap(url, &PartialReadError{URL: url, Bytes: n, Err: err})
+	}
+	return n, err
+}
+
+// contentRange parses the value of a Content-Range header, "bytes
+// first-last/size" or "bytes */size", returning the first byte position
+// (-1 in the second form) and the complete size (-1 if given as "*").
+func contentRange(h string) (start, size int64, ok bool) {
+	if !strings.HasPrefix(h, "bytes ") {
+		return 0, 0, false
+	}
+	rng, total, found := strings.Cut(strings.TrimPrefix(h, "bytes "), "/")
+	if !found {
+		return 0, 0, false
+	}
+	size = -1
+	if total != "*" {
+		var err error
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if rng == "*" {
+		return -1, size, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
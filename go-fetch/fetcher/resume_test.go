@@ -0,0 +1,85 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchResumable(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+	dest := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(dest, []byte(content[:300]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := FetchResumable(srv.URL, dest)
+	if b, _ := os.ReadFile(dest); err != nil || n != int64(len(content)) || string(b) != content {
+		t.Errorf("FetchResumable = %d, %v; want %d bytes, the rest appended", n, err, len(content))
+	}
+}
+
+func TestFetchResumableIdleTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			delay := 100 * time.Millisecond
+			if r.URL.Path == "/stall" && i == 1 {
+				delay = time.Second
+			}
+			time.Sleep(delay)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: srv.Client().Transport, Timeout: 300 * time.Millisecond}
+	dir := t.TempDir()
+
+	// 500ms in all, longer than the timeout, but never 300ms without data.
+	n, err := FetchResumableWithClient(client, srv.URL+"/slow", filepath.Join(dir, "slow"))
+	if err != nil || n != 25 {
+		t.Errorf("slow but steady body: FetchResumableWithClient = %d, %v; want 25 bytes", n, err)
+	}
+
+	var te *TimeoutError
+	var pe *PartialReadError
+	dest := filepath.Join(dir, "stall")
+	_, err = FetchResumableWithClient(client, srv.URL+"/stall", dest)
+	if !errors.As(err, &te) || !errors.As(err, &pe) {
+		t.Errorf("stalled body: err = %v, want a *TimeoutError wrapping a *PartialReadError", err)
+	}
+	if b, _ := os.ReadFile(dest); !bytes.Equal(b, []byte("chunkchunk")) {
+		t.Errorf("stalled body: kept %q, want the 10 bytes that arrived", b)
+	}
+}
@@ -0,0 +1,233 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxBackoffShift bounds the exponent of the backoff so that the delay
+// cannot overflow for large retry counts.
+const maxBackoffShift = 16
+
+// A RetryError reports that fetching URL failed after Attempts attempts;
+// Err is the error from the last one.
+type RetryError struct {
+	URL      string
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("fetch %s: failed after %d attempt(s): %v", RedactURL(e.URL), e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// DefaultMaxRetryAfter is the longest a retry waits for a Retry-After
+// header when RetryPolicy does not say otherwise.
+const DefaultMaxRetryAfter = time.Minute
+
+// A RetryPolicy says how FetchWithRetryPolicy retries a failed fetch.
+type RetryPolicy struct {
+	MaxRetries int           // retries after the first attempt
+	BaseDelay  time.Duration // delay before the first retry, doubled for each one after it
+
+	// MaxRetryAfter caps the wait asked for by a Retry-After header, so that
+	// a hostile server cannot stall the fetch indefinitely. Zero means
+	// DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+
+	// RetryNonIdempotent allows retrying requests whose method is not
+	// idempotent, such as POST and PATCH. By default only GET, HEAD, PUT,
+	// DELETE, OPTIONS and TRACE are retried: an attempt that fails after
+	// reaching the server may already have taken effect, and repeating a
+	// POST could, say, place an order twice.
+	RetryNonIdempotent bool
+}
+
+// FetchWithRetry prints the content found at url, retrying up to maxRetries
+// times after connection errors and 5xx responses. The delay before retry n
+// is baseDelay*2^(n-1) plus up to as much again of random jitter. 4xx
+// responses are not retried, since repeating the request will not help,
+// except for 429 Too Many Requests. The body is buffered so that a failed
+// attempt prints nothing.
+func FetchWithRetry(url string, maxRetries int, baseDelay time.Duration) (int64, error) {
+	return FetchWithRetryPolicy(url, RetryPolicy{MaxRetries: maxRetries, BaseDelay: baseDelay})
+}
+
+// FetchWithRetryPolicy is like FetchWithRetry but retries as p says. When a
+// 429 or 503 response carries a Retry-After header, the next retry waits
+// as long as it asks, up to p.MaxRetryAfter, instead of backing off.
+func FetchWithRetryPolicy(url string, p RetryPolicy) (int64, error) {
+	buf, err := p.do(http.MethodGet, url, func(w io.Writer) error {
+		_, err := fetchOK(context.Background(), w, url, DefaultTimeout)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(os.Stdout)
+}
+
+// do calls try, which sends a method request for url and writes the
+// response into the writer it is given, until it succeeds or p says to give
+// up, and returns what the successful attempt wrote. Each attempt writes to
+// a fresh buffer, so a failed one leaves no trace. A method that is not
+// idempotent is tried only once unless p.RetryNonIdempotent is set. If
+// every attempt fails the error is a *RetryError.
+func (p RetryPolicy) do(method, url string, try func(w io.Writer) error) (*bytes.Buffer, error) {
+	max := p.MaxRetries
+	if !idempotent(method) && !p.RetryNonIdempotent {
+		max = 0
+	}
+	var err error
+	attempt := 0
+	for attempt <= max {
+		if attempt > 0 {
+			time.Sleep(p.delay(err, attempt))
+		}
+		attempt++
+		var buf bytes.Buffer
+		if err = try(&buf); err == nil {
+			return &buf, nil
+		}
+		if !retryable(err) {
+			break
+		}
+		if attempt <= max {
+			logf(LevelInfo, "retrying %s: attempt %d failed: %v", RedactURL(url), attempt, err)
+		}
+	}
+	return nil, &RetryError{URL: url, Attempts: attempt, Err: err}
+}
+
+// delay returns how long to wait before retry number attempt (counting
+// from 1), which follows a failure with err.
+func (p RetryPolicy) delay(err error, attempt int) time.Duration {
+	var se *StatusError
+	if errors.As(err, &se) && se.RetryAfter > 0 {
+		max := p.MaxRetryAfter
+		if max == 0 {
+			max = DefaultMaxRetryAfter
+		}
+		if se.RetryAfter > max {
+			return max
+		}
+		return se.RetryAfter
+	}
+	return backoff(p.BaseDelay, attempt)
+}
+
+// fetchOK is like fetchTo but treats a 4xx or 5xx response as a
+// *StatusError without reading its body.
+func fetchOK(ctx context.Context, w io.Writer, url string, timeout time.Duration) (int64, error) {
+	return fetchOKWith(ctx, clientWithTimeout(timeout), w, url)
+}
+
+// fetchOKWith is like fetchOK but sends the request with client.
+func fetchOKWith(ctx context.Context, client *http.Client, w io.Writer, url string) (int64, error) {
+	resp, err := getWith(ctx, client, url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, statusError(url, resp)
+	}
+	return copyBody(ctx, w, resp.Body, url, client.Timeout)
+}
+
+// idempotent reports whether sending a request with method twice has the
+// same effect as sending it once, so that a failed one may be retried.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether a fetch that failed with err is worth repeating:
+// server errors, 429 Too Many Requests and transport failures are, other
+// client errors, cancellations, responses refused as too large and bodies
+// that failed a check are not.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500 || se.StatusCode == http.StatusTooManyRequests
+	}
+	var tle *TooLargeError
+	var ce *CheckError
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &tle) || errors.As(err, &ce) {
+		return false
+	}
+	return true
+}
+
+// backoff returns the delay before retry number attempt (counting from 1).
+func backoff(base time.Duration, attempt int) time.Duration {
+	shift := attempt - 1
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	d := base << uint(shift)
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)))
+}
+
+// parseRetryAfter returns the wait asked for by the value of a Retry-After
+// header, which is either a number of seconds or an HTTP date, measured
+// from now. It reports false if h is neither or asks for no wait.
+func parseRetryAfter(h string, now time.Time) (time.Duration, bool) {
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(h, 10, 64); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		if secs > int64(math.MaxInt64/time.Second) {
+			secs = int64(math.MaxInt64 / time.Second) // the cap in RetryPolicy.delay will apply
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(h)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	return d, d > 0
+}
@@ -0,0 +1,147 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer returns a server that answers with status fails times, then
+// with 200 OK and "ok", counting the requests in *n.
+func failingServer(status, fails int, n *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(n.Add(1)) <= fails {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+}
+
+func TestFetchWithRetry(t *testing.T) {
+	var n atomic.Int32
+	srv := failingServer(http.StatusServiceUnavailable, 2, &n)
+	defer srv.Close()
+	if got, err := FetchWithRetry(srv.URL, 3, time.Millisecond); err != nil || got != 2 || n.Load() != 3 {
+		t.Errorf("two 503s then ok: FetchWithRetry = %d, %v after %d requests; want 2 bytes after 3", got, err, n.Load())
+	}
+
+	n.Store(0)
+	srv404 := failingServer(http.StatusNotFound, 5, &n)
+	defer srv404.Close()
+	_, err := FetchWithRetry(srv404.URL, 3, time.Millisecond)
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != 1 || n.Load() != 1 {
+		t.Errorf("404: FetchWithRetry err = %v after %d requests; want a *RetryError after 1 attempt", err, n.Load())
+	}
+
+	n.Store(0)
+	srv500 := failingServer(http.StatusInternalServerError, 5, &n)
+	defer srv500.Close()
+	if _, err := FetchWithRetry(srv500.URL, 2, time.Millisecond); !errors.As(err, &re) || re.Attempts != 3 {
+		t.Errorf("500s: FetchWithRetry err = %v, want a *RetryError after 3 attempts", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 4; attempt++ {
+		d, base := backoff(10*time.Millisecond, attempt), 10*time.Millisecond<<(attempt-1)
+		if d < base || d >= 2*base {
+			t.Errorf("backoff(10ms, %d) = %v, want in [%v, %v)", attempt, d, base, 2*base)
+		}
+	}
+	if d := backoff(time.Second, 1000); d <= 0 {
+		t.Errorf("backoff(1s, 1000) = %v, want a positive delay", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		h    string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 11:59:00 GMT", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseRetryAfter(tt.h, now); ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.h, got, ok, tt.want, tt.ok)
+		}
+	}
+	if got, ok := parseRetryAfter("99999999999999999999", now); ok {
+		t.Errorf("parseRetryAfter(huge) = %v, %v; want it rejected as unparsable", got, ok)
+	}
+	if got, ok := parseRetryAfter("9999999999999", now); !ok || got <= 0 {
+		t.Errorf("parseRetryAfter(9999999999999) = %v, %v; want a positive duration", got, ok)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Millisecond, MaxRetryAfter: 10 * time.Second}
+	if d := p.delay(&StatusError{StatusCode: 503, RetryAfter: 2 * time.Second}, 1); d != 2*time.Second {
+		t.Errorf("delay with Retry-After 2s = %v, want 2s", d)
+	}
+	if d := p.delay(&StatusError{StatusCode: 429, RetryAfter: time.Hour}, 1); d != 10*time.Second {
+		t.Errorf("delay with Retry-After 1h = %v, want the 10s cap", d)
+	}
+	if d := (RetryPolicy{}).delay(&StatusError{StatusCode: 429, RetryAfter: time.Hour}, 1); d != DefaultMaxRetryAfter {
+		t.Errorf("delay with no MaxRetryAfter = %v, want DefaultMaxRetryAfter", d)
+	}
+	if d := p.delay(&StatusError{StatusCode: 503}, 1); d >= 2*time.Millisecond {
+		t.Errorf("delay without Retry-After = %v, want a backoff under 2ms", d)
+	}
+}
+
+func TestFetchWithRetryAfter(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	p := RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxRetryAfter: 50 * time.Millisecond}
+	if _, err := FetchWithRetryPolicy(srv.URL, p); err != nil || n.Load() != 2 {
+		t.Fatalf("FetchWithRetryPolicy = %v after %d requests; want success after 2", err, n.Load())
+	}
+	if d := time.Since(start); d < 50*time.Millisecond || d >= time.Second {
+		t.Errorf("retry after %v, want the 1s Retry-After capped at 50ms", d)
+	}
+}
@@ -0,0 +1,191 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxRobotsSize is how much of a robots.txt file is parsed; RFC 9309 asks
+// crawlers to read at least 500 KiB.
+const maxRobotsSize = 500 << 10
+
+// Robots holds the rules a robots.txt file sets for one user agent.
+type Robots struct {
+	rules []robotsRule
+}
+
+// A robotsRule is one Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	length  int            // of the pattern: the longest matching rule wins
+	pattern *regexp.Regexp // the path pattern, with * and a final $ translated
+}
+
+// ParseRobots parses the robots.txt file body and returns the rules for the
+// user agent whose product token, such as "go-workspace-fetcher", is agent:
+// those of the groups naming it, or if there are none, of the groups for *.
+// Unknown lines and malformed patterns are ignored.
+func ParseRobots(body []byte, agent string) *Robots {
+	if len(body) > maxRobotsSize {
+		body = body[:maxRobotsSize]
+	}
+	agent = strings.ToLower(agent)
+	var mine, star []robotsRule
+	var inMine, inStar bool // whether the current group applies to agent, or to *
+	matched := false        // whether any group names agent, even one with no rules kept
+	inRules := false        // whether the current group has rules yet, so that a user-agent line starts a new group
+	for _, line := range strings.Split(string(body), "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				inMine, inStar, inRules = false, false, false
+			}
+			switch strings.ToLower(value) {
+			case agent:
+				inMine, matched = true, true
+			case "*":
+				inStar = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // "Disallow:" with no path allows everything
+			}
+			re, err := regexp.Compile(robotsPattern(value))
+			if err != nil {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: re}
+			if inMine {
+				mine = append(mine, rule)
+			}
+			if inStar {
+				star = append(star, rule)
+			}
+		}
+	}
+	if matched {
+		return &Robots{rules: mine}
+	}
+	return &Robots{rules: star}
+}
+
+// robotsPattern translates a robots.txt path pattern into a regular
+// expression: * matches any run of characters and a final $ anchors the
+// pattern to the end of the path; otherwise the pattern is a prefix.
+func robotsPattern(p string) string {
+	anchored := strings.HasSuffix(p, "$")
+	p = strings.TrimSuffix(p, "$")
+	re := "^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+	if anchored {
+		re += "$"
+	}
+	return re
+}
+
+// Allowed reports whether the rules allow fetching rawURL. The longest
+// pattern matching its path and query decides; if an Allow and a Disallow
+// pattern are equally long, Allow wins. A URL no rule matches is allowed,
+// as is /robots.txt itself.
+func (r *Robots) Allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if rule.length < longest || (rule.length == longest && !rule.allow) || !rule.pattern.MatchString(path) {
+			continue
+		}
+		allowed, longest = rule.allow, rule.length
+	}
+	return allowed
+}
+
+// robotsAgent is the product token of DefaultUserAgent, by which robots.txt
+// files address this package's crawler.
+var robotsAgent, _, _ = strings.Cut(DefaultUserAgent, "/")
+
+// A robotsCache fetches and parses the robots.txt file of each host once.
+// It is not safe for concurrent use.
+type robotsCache struct {
+	client *http.Client       // nil means a client with DefaultTimeout
+	sites  map[string]*Robots // by scheme and host
+}
+
+// allowed reports whether the robots.txt file of rawURL's site allows
+// fetching it, fetching the file if it has not been seen yet. As RFC 9309
+// says, a missing file (a 4xx status) allows everything and a server error
+// disallows everything; a file that cannot be fetched at all also allows
+// everything, so that the pages fail with errors of their own.
+func (c *robotsCache) allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return true
+	}
+	site := u.Scheme + "://" + strings.ToLower(u.Host)
+	r, ok := c.sites[site]
+	if !ok {
+		r = fetchRobots(c.client, site+"/robots.txt")
+		if c.sites == nil {
+			c.sites = make(map[string]*Robots)
+		}
+		c.sites[site] = r
+	}
+	return r.Allowed(rawURL)
+}
+
+// fetchRobots fetches, with client, and parses the robots.txt file at
+// rawURL.
+func fetchRobots(client *http.Client, rawURL string) *Robots {
+	res := FetchBatch([]string{rawURL}, BatchOptions{Client: client, KeepBody: true})[0]
+	switch {
+	case res.Err != nil, res.StatusCode >= 400 && res.StatusCode < 500:
+		return &Robots{}
+	case res.StatusCode >= 500:
+		return &Robots{rules: []robotsRule{{length: 1, pattern: regexp.MustCompile("^/")}}}
+	}
+	return ParseRobots(res.Body, robotsAgent)
+}
@@ -0,0 +1,49 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import "testing"
+
+func TestParseRobots(t *testing.T) {
+	for _, test := range []struct {
+		body    string
+		rawURL  string
+		allowed bool
+	}{
+		{"User-agent: *\nDisallow: /", "http://x/page", false},
+		{"User-agent: *\nDisallow: /private\n", "http://x/private/a", false},
+		{"User-agent: *\nDisallow: /private\n", "http://x/public", true},
+		{"User-agent: *\nDisallow: /\nAllow: /open\n", "http://x/open/a", true},
+		{"User-agent: *\nDisallow: /*.pdf$\n", "http://x/a.pdf", false},
+		{"User-agent: *\nDisallow: /*.pdf$\n", "http://x/a.pdf.html", true},
+		{"User-agent: go-workspace-fetcher\nDisallow: /mine\n\nUser-agent: *\nDisallow: /", "http://x/page", true},
+		{"User-agent: go-workspace-fetcher\nDisallow: /mine\n\nUser-agent: *\nDisallow: /", "http://x/mine", false},
+		{"User-agent: go-workspace-fetcher\nDisallow:\n\nUser-agent: *\nDisallow: /", "http://x/page", true},
+		{"User-agent: other\nUser-agent: go-workspace-fetcher\nDisallow:\n\nUser-agent: *\nDisallow: /", "http://x/page", true},
+		{"User-agent: *\nDisallow: /", "http://x/robots.txt", true},
+	} {
+		if got := ParseRobots([]byte(test.body), "go-workspace-fetcher").Allowed(test.rawURL); got != test.allowed {
+			t.Errorf("ParseRobots(%q).Allowed(%s) = %t, want %t", test.body, test.rawURL, got, test.allowed)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxSitemapSize is the largest sitemap, once decompressed, that
+// ParseSitemap accepts: the 50 MB the sitemaps protocol allows.
+const MaxSitemapSize = 50 << 20
+
+// maxSitemapDepth is how deeply FetchSitemap follows sitemap index files
+// that list other index files, which the protocol forbids but some sites
+// publish anyway.
+const maxSitemapDepth = 4
+
+// A sitemapDoc is either kind of sitemap file: a <urlset> listing pages or
+// a <sitemapindex> listing other sitemaps.
+type sitemapDoc struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// ParseSitemap returns the <loc> URLs of the sitemap body: in pages, those
+// of a <urlset>, and in sitemaps, the other sitemaps named by a
+// <sitemapindex>. A body compressed with gzip, as sitemap.xml.gz files
+// are, is decompressed first.
+func ParseSitemap(body []byte) (pages, sitemaps []string, err error) {
+	var r io.Reader = bytes.NewReader(body)
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) { // the gzip magic number
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxSitemapSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) > MaxSitemapSize {
+		return nil, nil, fmt.Errorf("sitemap larger than %d bytes", MaxSitemapSize)
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			pages = append(pages, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return pages, sitemaps, nil
+}
+
+// FetchSitemap fetches the sitemap at url and returns the URLs of the pages
+// it lists, in order and each once. If it is a sitemap index, the sitemaps
+// it names are fetched, several at a time, and their pages returned in the
+// order of the index. If some sitemap cannot be fetched or parsed, the
+// pages of the others are still returned, along with an error joining the
+// reasons.
+func FetchSitemap(url string) ([]string, error) {
+	var pages []string
+	var errs []error
+	seen := make(map[string]bool)         // pages already listed
+	visited := map[string]bool{url: true} // sitemaps already fetched
+	opts := BatchOptions{Concurrency: crawlConcurrency, KeepBody: true}
+	level := []string{url}
+	for depth := 0; len(level) > 0; depth++ {
+		var next []string
+		for _, r := range FetchBatch(level, opts) {
+			if r.Failed() {
+				errs = append(errs, r.err())
+				continue
+			}
+			found, sitemaps, err := ParseSitemap(r.Body)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sitemap %s: %w", RedactURL(r.URL), err))
+				continue
+			}
+			for _, p := range found {
+				if !seen[p] {
+					seen[p] = true
+					pages = append(pages, p)
+				}
+			}
+			for _, s := range sitemaps {
+				if visited[s] {
+					continue
+				}
+				visited[s] = true
+				if depth == maxSitemapDepth {
+					errs = append(errs, fmt.Errorf("sitemap %s: index files nested more than %d deep", RedactURL(s), maxSitemapDepth))
+					continue
+				}
+				next = append(next, s)
+			}
+		}
+		level = next
+	}
+	return pages, errors.Join(errs...)
+}
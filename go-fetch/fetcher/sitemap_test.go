@@ -0,0 +1,79 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchSitemap(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`<urlset><url><loc>http://site/p2</loc></url><url><loc>http://site/p3</loc></url></urlset>`))
+	zw.Close()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(strings.ReplaceAll(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc> SRV/a.xml </loc></sitemap>
+  <sitemap><loc>SRV/b.xml.gz</loc></sitemap>
+  <sitemap><loc>SRV/missing.xml</loc></sitemap>
+  <sitemap><loc>SRV/sitemap.xml</loc></sitemap>
+</sitemapindex>`, "SRV", srv.URL)))
+		case "/a.xml":
+			w.Write([]byte(`<urlset><url><loc>http://site/p1</loc></url><url><loc>http://site/p2</loc></url></urlset>`))
+		case "/b.xml.gz":
+			w.Write(gz.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	pages, err := FetchSitemap(srv.URL + "/sitemap.xml")
+	if strings.Join(pages, " ") != "http://site/p1 http://site/p2 http://site/p3" {
+		t.Errorf("FetchSitemap = %q, want p1, p2 and p3, each once, in index order", pages)
+	}
+	var se *StatusError
+	if !errors.As(err, &se) || se.URL != srv.URL+"/missing.xml" {
+		t.Errorf("FetchSitemap err = %v, want the 404 of missing.xml", err)
+	}
+}
+
+func TestParseSitemap(t *testing.T) {
+	if _, _, err := ParseSitemap([]byte("<urlset><url>")); err == nil {
+		t.Error("ParseSitemap of truncated XML succeeded, want an error")
+	}
+	pages, sitemaps, err := ParseSitemap([]byte(`<urlset><url><loc></loc></url><url><loc>http://a/</loc></url></urlset>`))
+	if err != nil || len(pages) != 1 || pages[0] != "http://a/" || sitemaps != nil {
+		t.Errorf("ParseSitemap = %q, %q, %v; want the one non-empty page", pages, sitemaps, err)
+	}
+}
@@ -0,0 +1,105 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultSpeedInterval is how often FetchWithSpeed reports the transfer
+// when it is given no interval.
+const DefaultSpeedInterval = 500 * time.Millisecond
+
+// A SpeedFunc is told how many bytes of a body have been read so far and
+// how long reading them has taken.
+type SpeedFunc func(n int64, elapsed time.Duration)
+
+// Speed returns the average transfer rate, in bytes per second, of n bytes
+// read in elapsed.
+func Speed(n int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
+
+// A speedReader counts the bytes read through it and calls report with the
+// count at most once per interval, and once more at the end of the input.
+type speedReader struct {
+	r        io.Reader
+	report   SpeedFunc
+	interval time.Duration
+	start    time.Time
+	last     time.Time // when report was last called
+	n        int64
+}
+
+func (s *speedReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.n += int64(n)
+	now := time.Now()
+	if err == io.EOF || now.Sub(s.last) >= s.interval {
+		s.last = now
+		s.report(s.n, now.Sub(s.start))
+	}
+	return n, err
+}
+
+// FetchWithSpeed is like FetchTo but calls report every interval while the
+// body downloads, and once when it is complete, with the bytes read so far
+// and the time taken, so that the caller can show the transfer rate as it
+// changes. If interval is zero, DefaultSpeedInterval is used. Since the
+// body may be large, DefaultTimeout bounds not the whole transfer but each
+// wait for data: for the response, and between reads of the body.
+func FetchWithSpeed(w io.Writer, url string, interval time.Duration, report SpeedFunc) (int64, error) {
+	return FetchWithSpeedWithClient(clientWithTimeout(DefaultTimeout), w, url, interval, report)
+}
+
+// FetchWithSpeedWithClient is like FetchWithSpeed but sends the request
+// with client, whose Timeout bounds each wait for data as DefaultTimeout
+// does for FetchWithSpeed. Zero means no limit.
+func FetchWithSpeedWithClient(client *http.Client, w io.Writer, url string, interval time.Duration, report SpeedFunc) (int64, error) {
+	if interval <= 0 {
+		interval = DefaultSpeedInterval
+	}
+	c := *client
+	c.Timeout = 0 // the idle timer takes its place
+	timer := newIdleTimer(context.Background(), client.Timeout)
+	defer timer.stop()
+	ctx := timer.ctx
+	resp, err := getWith(ctx, &c, url)
+	if err != nil {
+		return 0, timer.wrap(url, err)
+	}
+	defer resp.Body.Close()
+	now := time.Now()
+	body := &speedReader{r: timer.reader(resp.Body), report: report, interval: interval, start: now, last: now}
+	n, err := copyBody(ctx, w, body, url, client.Timeout)
+	if err != nil && timer.expired() {
+		err = timer.wrap(url, &PartialReadError{URL: url, Bytes: n, Err: err})
+	}
+	return n, err
+}
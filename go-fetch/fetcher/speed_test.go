@@ -0,0 +1,62 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchWithSpeedWithClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			delay := 100 * time.Millisecond
+			if r.URL.Path == "/stall" && i == 1 {
+				delay = time.Second
+			}
+			time.Sleep(delay)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: srv.Client().Transport, Timeout: 300 * time.Millisecond}
+
+	// 500ms in all, longer than the timeout, but never 300ms without data.
+	var buf bytes.Buffer
+	var last int64
+	n, err := FetchWithSpeedWithClient(client, &buf, srv.URL+"/slow", 50*time.Millisecond, func(n int64, elapsed time.Duration) { last = n })
+	if err != nil || n != 25 || buf.String() != "chunkchunkchunkchunkchunk" || last != 25 {
+		t.Errorf("slow but steady body: FetchWithSpeedWithClient = %d, %v, last report %d; want 25 bytes", n, err, last)
+	}
+
+	var te *TimeoutError
+	n, err = FetchWithSpeedWithClient(client, &bytes.Buffer{}, srv.URL+"/stall", 0, func(int64, time.Duration) {})
+	if !errors.As(err, &te) || n != 10 {
+		t.Errorf("stalled body: FetchWithSpeedWithClient = %d, %v; want 10 bytes and a *TimeoutError", n, err)
+	}
+}
@@ -0,0 +1,76 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// A HashState maps each URL to the hex SHA-256 of the content last fetched
+// from it, so that a later run can tell which URLs have changed. It is
+// kept between runs in a JSON file by LoadHashState and Save.
+type HashState map[string]string
+
+// LoadHashState reads the HashState saved in the named file. A file that
+// does not exist yet holds an empty state, as on the first run.
+func LoadHashState(name string) (HashState, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return HashState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := HashState{}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("state file %s: %v", name, err)
+	}
+	return s, nil
+}
+
+// Save writes s to the named file as JSON, replacing it atomically so that
+// a crash never leaves it half written.
+func (s HashState) Save(name string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(name, append(data, '\n'))
+}
+
+// Update records the checksum of r, which must have been fetched with
+// BatchOptions.Checksum set, and reports whether it differs from the one
+// recorded before, as it does for a URL never seen. A failed fetch changes
+// nothing and reports false.
+func (s HashState) Update(r Result) (changed bool) {
+	if r.Failed() || r.SHA256 == "" {
+		return false
+	}
+	old, seen := s[r.URL]
+	s[r.URL] = r.SHA256
+	return !seen || old != r.SHA256
+}
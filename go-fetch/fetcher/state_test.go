@@ -0,0 +1,71 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashState(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "state.json")
+	s, err := LoadHashState(name)
+	if err != nil || len(s) != 0 {
+		t.Fatalf("LoadHashState of a new file = %v, %v; want an empty state", s, err)
+	}
+
+	a := Result{URL: "http://a/", StatusCode: 200, SHA256: "aaaa"}
+	if !s.Update(a) || s.Update(a) {
+		t.Error("Update of a new URL, then of the same content: want changed, then unchanged")
+	}
+	if a.SHA256 = "bbbb"; !s.Update(a) {
+		t.Error("Update with new content: want changed")
+	}
+	for _, r := range []Result{
+		{URL: "http://a/", StatusCode: 500, SHA256: "cccc"},
+		{URL: "http://a/", Err: errors.New("refused")},
+		{URL: "http://b/", StatusCode: 200},
+	} {
+		if s.Update(r) {
+			t.Errorf("Update(%+v) reported a change", r)
+		}
+	}
+	if len(s) != 1 || s["http://a/"] != "bbbb" {
+		t.Errorf("state = %v, want only http://a/ at bbbb", s)
+	}
+
+	if err := s.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadHashState(name); err != nil || len(loaded) != 1 || loaded["http://a/"] != "bbbb" {
+		t.Errorf("LoadHashState after Save = %v, %v; want %v", loaded, err, s)
+	}
+	if err := os.WriteFile(name, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHashState(name); err == nil {
+		t.Error("LoadHashState of a corrupt file succeeded, want an error")
+	}
+}
@@ -0,0 +1,133 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"sort"
+	"time"
+)
+
+// A Summary aggregates the Results of a batch of fetches.
+type Summary struct {
+	Total      int           // number of fetches
+	Succeeded  int           // fetches that did not fail
+	Failed     int           // fetches for which Result.Failed reports true
+	TotalBytes int64         // body bytes read by all fetches, including failed ones
+	Min        time.Duration // lowest latency of the successful fetches
+	Max        time.Duration // highest latency of the successful fetches
+	Mean       time.Duration // mean latency of the successful fetches
+	P50        time.Duration // median latency of the successful fetches
+	P95        time.Duration // 95th percentile latency of the successful fetches
+	P99        time.Duration // 99th percentile latency of the successful fetches
+	Busy       time.Duration // sum of the Elapsed times of all the fetches
+	Wall       time.Duration // from the earliest Start to the latest End: the wall-clock time of the batch
+
+	// ByHost breaks the fetches down by the host, with any port, of their
+	// URLs, in lower case.
+	ByHost map[string]HostSummary
+
+	// ByLabel breaks the fetches down in the same way by their
+	// Result.Label; those without a label are counted under "".
+	ByLabel map[string]HostSummary
+}
+
+// A HostSummary counts the fetches of a batch from one host, or with one
+// label.
+type HostSummary struct {
+	Requests int   // number of fetches
+	Failed   int   // fetches for which Result.Failed reports true
+	Bytes    int64 // body bytes read by them
+}
+
+// add returns h with r counted in it.
+func (h HostSummary) add(r Result) HostSummary {
+	h.Requests++
+	h.Bytes += r.Bytes
+	if r.Failed() {
+		h.Failed++
+	}
+	return h
+}
+
+// Summarize aggregates results into a Summary. Latencies are computed over
+// the successful fetches only; if there are none, they are zero. Busy
+// divided by Wall is the speedup that fetching concurrently achieved over
+// fetching the same URLs one after another.
+func Summarize(results []Result) Summary {
+	s := Summary{Total: len(results), ByHost: make(map[string]HostSummary), ByLabel: make(map[string]HostSummary)}
+	var latencies []time.Duration
+	var sum time.Duration
+	var first, last time.Time
+	for _, r := range results {
+		s.TotalBytes += r.Bytes
+		s.Busy += r.Elapsed
+		if first.IsZero() || r.Start.Before(first) {
+			first = r.Start
+		}
+		if r.End.After(last) {
+			last = r.End
+		}
+		host := hostKey(r.URL)
+		s.ByHost[host] = s.ByHost[host].add(r)
+		s.ByLabel[r.Label] = s.ByLabel[r.Label].add(r)
+		if r.Failed() {
+			s.Failed++
+			continue
+		}
+		s.Succeeded++
+		latencies = append(latencies, r.Elapsed)
+		sum += r.Elapsed
+	}
+	if !first.IsZero() {
+		s.Wall = last.Sub(first)
+	}
+	if len(latencies) == 0 {
+		return s
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s.Min, s.Max = latencies[0], latencies[len(latencies)-1]
+	s.Mean = sum / time.Duration(len(latencies))
+	s.P50 = percentile(latencies, 50)
+	s.P95 = percentile(latencies, 95)
+	s.P99 = percentile(latencies, 99)
+	return s
+}
+
+// Rate returns the number of fetches completed per second of wall-clock
+// time, or zero if no time passed.
+func (s Summary) Rate() float64 {
+	if s.Wall <= 0 {
+		return 0
+	}
+	return float64(s.Total) / s.Wall.Seconds()
+}
+
+// percentile returns the p-th percentile of sorted, which must not be empty,
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,159 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		{URL: "http://a/1", StatusCode: 200, Bytes: 100, Elapsed: 10 * time.Millisecond},
+		{URL: "http://a/2", StatusCode: 200, Bytes: 50, Elapsed: 30 * time.Millisecond},
+		{URL: "http://a/3", StatusCode: 500, Bytes: 7, Elapsed: time.Second},
+		{URL: "http://a/4", Err: errors.New("refused")},
+	}
+	s := Summarize(results)
+	if s.Total != 4 || s.Succeeded != 2 || s.Failed != 2 || s.TotalBytes != 157 {
+		t.Errorf("Summarize = %d total, %d succeeded, %d failed, %d bytes; want 4, 2, 2, 157", s.Total, s.Succeeded, s.Failed, s.TotalBytes)
+	}
+	if s.Mean != 20*time.Millisecond || s.P95 != 30*time.Millisecond {
+		t.Errorf("Summarize: mean %v, p95 %v; want 20ms and 30ms, from the successes only", s.Mean, s.P95)
+	}
+	if s := Summarize(results[3:]); s.Mean != 0 || s.P95 != 0 || s.Failed != 1 {
+		t.Errorf("Summarize of failures only = %+v, want zero latencies", s)
+	}
+}
+
+func TestSummarizeWall(t *testing.T) {
+	var peak int
+	srv := inFlightServer(&peak)
+	defer srv.Close()
+	urls := []string{srv.URL + "/1", srv.URL + "/2", srv.URL + "/3", srv.URL + "/4"}
+
+	results := FetchAll(urls, len(urls))
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Start.IsZero() || r.End.Sub(r.Start) != r.Elapsed {
+			t.Errorf("%s: Start %v, End %v, Elapsed %v; want Elapsed to be End minus Start", r.URL, r.Start, r.End, r.Elapsed)
+		}
+	}
+	s := Summarize(results)
+	if s.Busy < 4*20*time.Millisecond || s.Wall <= 0 || s.Wall >= s.Busy {
+		t.Errorf("Summarize: Busy %v, Wall %v; want four 20ms fetches overlapping in wall-clock time", s.Busy, s.Wall)
+	}
+	if s := Summarize(nil); s.Wall != 0 || s.Busy != 0 {
+		t.Errorf("Summarize(nil): Busy %v, Wall %v; want zero", s.Busy, s.Wall)
+	}
+}
+
+func TestSummarizePercentiles(t *testing.T) {
+	var results []Result
+	for i := 100; i >= 1; i-- {
+		results = append(results, Result{StatusCode: 200, Elapsed: time.Duration(i) * time.Millisecond})
+	}
+	s := Summarize(results)
+	got := []time.Duration{s.Min, s.P50, s.P95, s.P99, s.Max}
+	want := []time.Duration{time.Millisecond, 50 * time.Millisecond, 95 * time.Millisecond, 99 * time.Millisecond, 100 * time.Millisecond}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Summarize of 1ms to 100ms: min, p50, p95, p99, max = %v, want %v", got, want)
+			break
+		}
+	}
+	if s := Summarize(results[99:]); s.P50 != time.Millisecond || s.P99 != time.Millisecond {
+		t.Errorf("Summarize of one fetch: p50 %v, p99 %v; want 1ms", s.P50, s.P99)
+	}
+}
+
+func TestSummaryRate(t *testing.T) {
+	if r := (Summary{Total: 10, Wall: 2 * time.Second}).Rate(); r != 5 {
+		t.Errorf("Rate of 10 fetches in 2s = %v, want 5", r)
+	}
+	if r := (Summary{Total: 10}).Rate(); r != 0 {
+		t.Errorf("Rate with no wall-clock time = %v, want 0", r)
+	}
+}
+
+func TestSummarizeByHost(t *testing.T) {
+	s := Summarize([]Result{
+		{URL: "http://A.example/1", StatusCode: 200, Bytes: 10},
+		{URL: "http://a.example/2", StatusCode: 404, Bytes: 5},
+		{URL: "http://a.example:8080/", StatusCode: 200, Bytes: 1},
+		{URL: "http://b.example/", Err: errors.New("refused")},
+		{URL: "%zz", Err: errors.New("bad URL")},
+	})
+	want := map[string]HostSummary{
+		"a.example":      {Requests: 2, Failed: 1, Bytes: 15},
+		"a.example:8080": {Requests: 1, Bytes: 1},
+		"b.example":      {Requests: 1, Failed: 1},
+		"":               {Requests: 1, Failed: 1},
+	}
+	if len(s.ByHost) != len(want) {
+		t.Errorf("ByHost = %v, want %v", s.ByHost, want)
+	}
+	for host, h := range want {
+		if s.ByHost[host] != h {
+			t.Errorf("ByHost[%q] = %+v, want %+v", host, s.ByHost[host], h)
+		}
+	}
+}
+
+func TestFetchBatchLabels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("up"))
+	}))
+	defer srv.Close()
+	urls := []string{srv.URL + "/a", srv.URL + "/down", srv.URL + "/b", srv.URL + "/c"}
+
+	results := FetchBatch(urls, BatchOptions{Labels: []string{"api", "api", "web"}})
+	for i, want := range []string{"api", "api", "web", ""} {
+		if results[i].Label != want {
+			t.Errorf("result %d has label %q, want %q", i, results[i].Label, want)
+		}
+	}
+	s := Summarize(results)
+	want := map[string]HostSummary{
+		"api": {Requests: 2, Failed: 1, Bytes: 2},
+		"web": {Requests: 1, Bytes: 2},
+		"":    {Requests: 1, Bytes: 2},
+	}
+	if len(s.ByLabel) != len(want) {
+		t.Errorf("ByLabel = %v, want %v", s.ByLabel, want)
+	}
+	for label, h := range want {
+		if s.ByLabel[label] != h {
+			t.Errorf("ByLabel[%q] = %+v, want %+v", label, s.ByLabel[label], h)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// DefaultTimeout is the time allowed for a whole request, including reading
+// the body, when the caller does not choose one.
+const DefaultTimeout = 30 * time.Second
+
+// A TimeoutError reports that fetching URL took longer than Timeout.
+type TimeoutError struct {
+	URL     string
+	Timeout time.Duration
+	Err     error // underlying error returned by the HTTP client
+}
+
+func (e *TimeoutError) Error() string {
+	var pe *PartialReadError
+	if errors.As(e.Err, &pe) {
+		return fmt.Sprintf("fetch %s: timed out after %v, having read %d bytes", RedactURL(e.URL), e.Timeout, pe.Bytes)
+	}
+	return fmt.Sprintf("fetch %s: timed out after %v", RedactURL(e.URL), e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// FetchWithTimeout prints the content found at url. If the request, including
+// the body copy, takes longer than timeout it returns a *TimeoutError.
+func FetchWithTimeout(url string, timeout time.Duration) error {
+	_, err := fetchTo(context.Background(), os.Stdout, url, timeout)
+	return err
+}
+
+// timeoutError wraps err in a *TimeoutError if it was caused by a timeout,
+// and returns it unchanged otherwise.
+func timeoutError(url string, timeout time.Duration, err error) error {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return &TimeoutError{URL: url, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// errIdle is the cause given to the context of a request that an idleTimer
+// cancelled.
+var errIdle = errors.New("no data arrived within the idle timeout")
+
+// An idleTimer cancels a request once timeout passes without progress:
+// while waiting for the response, or between reads of its body. Unlike a
+// client's Timeout, it lets a body that keeps arriving take as long as it
+// needs. A zero timeout never cancels.
+type idleTimer struct {
+	ctx     context.Context // the request's context, which the timer cancels
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+	timer   *time.Timer // nil if timeout is zero
+}
+
+// newIdleTimer starts an idleTimer. The request must be sent with the
+// timer's ctx, derived from ctx, and the caller must call stop when done.
+func newIdleTimer(ctx context.Context, timeout time.Duration) *idleTimer {
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := &idleTimer{ctx: ctx, cancel: cancel, timeout: timeout}
+	if timeout > 0 {
+		t.timer = time.AfterFunc(timeout, func() { cancel(errIdle) })
+	}
+	return t
+}
+
+// reader returns a reader of r that restarts the timer whenever data
+// arrives.
+func (t *idleTimer) reader(r io.Reader) io.Reader {
+	if t.timer == nil {
+		return r
+	}
+	return &idleReader{r: r, t: t}
+}
+
+// expired reports whether the timer has cancelled the request.
+func (t *idleTimer) expired() bool {
+	return errors.Is(context.Cause(t.ctx), errIdle)
+}
+
+// wrap returns err as a *TimeoutError for url if it arose because the
+// timer cancelled the request, and unchanged otherwise.
+func (t *idleTimer) wrap(url string, err error) error {
+	if err != nil && t.expired() {
+		return &TimeoutError{URL: url, Timeout: t.timeout, Err: err}
+	}
+	return err
+}
+
+// stop stops the timer and releases its context.
+func (t *idleTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.cancel(nil)
+}
+
+// An idleReader restarts an idleTimer whenever a read returns data.
+type idleReader struct {
+	r io.Reader
+	t *idleTimer
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.timer.Reset(r.t.timeout)
+	}
+	return n, err
+}
@@ -0,0 +1,112 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// A TooLargeError reports that the response to URL was refused because its
+// header or body was larger than the limit set in ClientOptions.
+type TooLargeError struct {
+	URL   string
+	Part  string // "header" or "body"
+	Limit int64  // in bytes
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("fetch %s: too large: %s over %d bytes", RedactURL(e.URL), e.Part, e.Limit)
+}
+
+// A sizeLimiter is a RoundTripper enforcing the size limits of
+// ClientOptions on the responses of rt. It sees the body as it comes over
+// the wire; send applies maxBody again once the body is decompressed.
+type sizeLimiter struct {
+	rt        http.RoundTripper
+	maxHeader int64
+	maxBody   int64
+}
+
+func (l *sizeLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	resp, err := l.rt.RoundTrip(req)
+	if err != nil {
+		// net/http reports an oversized header only in the text of its error.
+		if l.maxHeader > 0 && strings.Contains(err.Error(), "server response headers exceeded") {
+			return nil, &TooLargeError{URL: url, Part: "header", Limit: l.maxHeader}
+		}
+		return nil, err
+	}
+	if l.maxBody <= 0 {
+		return resp, nil
+	}
+	if resp.ContentLength > l.maxBody {
+		resp.Body.Close() // without reading it
+		return nil, &TooLargeError{URL: url, Part: "body", Limit: l.maxBody}
+	}
+	resp.Body = &limitedBody{body: resp.Body, left: l.maxBody, err: &TooLargeError{URL: url, Part: "body", Limit: l.maxBody}}
+	return resp, nil
+}
+
+// A limitedBody fails with err once more than left bytes have been read
+// from body.
+type limitedBody struct {
+	body io.ReadCloser
+	left int64
+	err  error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left < 0 {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1] // one byte over is enough to tell
+	}
+	n, err := b.body.Read(p)
+	b.left -= int64(n)
+	if b.left < 0 {
+		return n + int(b.left), b.err // the bytes within the limit, then the error
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error { return b.body.Close() }
+
+// bodyLimit returns the MaxContentLength that rt, a transport from
+// NewClient, enforces, or zero if it enforces none.
+func bodyLimit(rt http.RoundTripper) int64 {
+	for {
+		switch t := rt.(type) {
+		case *headerTransport:
+			rt = t.rt
+		case *sizeLimiter:
+			return t.maxBody
+		default:
+			return 0
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks the latency of a fetch down into phases, much as curl -w
+// does. A phase that did not happen is zero: there is no DNS lookup for an
+// IP address, and no connect or TLS handshake on a reused connection.
+type Timing struct {
+	DNS          time.Duration // resolving the host name
+	Connect      time.Duration // establishing the TCP connection
+	TLSHandshake time.Duration // negotiating TLS
+	FirstByte    time.Duration // from the start of the fetch to the first response byte
+	Transfer     time.Duration // from the first response byte to the end of the body
+}
+
+// A tracer records the Timing of one request. Some hooks can run
+// concurrently, for example when dialing several addresses at once, so its
+// fields are guarded by mu.
+type tracer struct {
+	mu                            sync.Mutex
+	start                         time.Time
+	dnsStart, connStart, tlsStart time.Time
+	timing                        Timing
+}
+
+// traceRequest returns a copy of req whose context records the phases of
+// the request in the returned tracer.
+func traceRequest(req *http.Request) (*http.Request, *tracer) {
+	tr := &tracer{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { tr.mark(&tr.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { tr.since(&tr.timing.DNS, &tr.dnsStart) },
+		ConnectStart: func(network, addr string) {
+			tr.mark(&tr.connStart)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tr.since(&tr.timing.Connect, &tr.connStart)
+		},
+		TLSHandshakeStart: func() { tr.mark(&tr.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tr.since(&tr.timing.TLSHandshake, &tr.tlsStart)
+		},
+		GotFirstResponseByte: func() { tr.since(&tr.timing.FirstByte, &tr.start) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), tr
+}
+
+// mark records the current time in *t.
+func (tr *tracer) mark(t *time.Time) {
+	tr.mu.Lock()
+	*t = time.Now()
+	tr.mu.Unlock()
+}
+
+// since sets *d to the time elapsed since *t.
+func (tr *tracer) since(d *time.Duration, t *time.Time) {
+	tr.mu.Lock()
+	*d = time.Since(*t)
+	tr.mu.Unlock()
+}
+
+// done returns the Timing of a request whose body was completely read after
+// elapsed.
+func (tr *tracer) done(elapsed time.Duration) Timing {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	t := tr.timing
+	if t.FirstByte > 0 {
+		t.Transfer = elapsed - t.FirstByte
+	}
+	return t
+}
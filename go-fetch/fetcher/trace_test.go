@@ -0,0 +1,55 @@
+package fetcher
+
+// MIT License
+
+// Copyright (c) 2022 Mobile Data Books, LLC
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTiming(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond) // before the first byte
+		w.Write([]byte("head"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond) // during the transfer
+		w.Write([]byte("tail"))
+	}))
+	defer srv.Close()
+	r, err := FetchResponseWithClient(NewClient(ClientOptions{}), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := r.Timing
+	if tm.FirstByte < 50*time.Millisecond || tm.Transfer < 40*time.Millisecond {
+		t.Errorf("Timing = %+v, want at least 50ms to the first byte and 40ms of transfer", tm)
+	}
+	if tm.FirstByte+tm.Transfer != r.Elapsed {
+		t.Errorf("first byte %v + transfer %v != elapsed %v", tm.FirstByte, tm.Transfer, r.Elapsed)
+	}
+	if tm.DNS != 0 || tm.TLSHandshake != 0 {
+		t.Errorf("Timing = %+v, want no DNS lookup or TLS for http://127.0.0.1", tm)
+	}
+}